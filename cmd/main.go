@@ -3,13 +3,20 @@ package main
 import (
 	"go-api/internal"
 	"go-api/internal/http"
+	"go-api/internal/http/middlewares"
+	"go-api/internal/mail"
+	"go-api/internal/oidc"
+	"go-api/internal/password"
 	"go-api/internal/pgx"
 	"strconv"
 
 	"context"
+	"fmt"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
@@ -90,7 +97,15 @@ type Main struct {
 	HTTPServer *http.Server
 
 	// Services
-	UserService internal.UserService
+	UserService         internal.UserService
+	RoleService         internal.RoleService
+	RefreshTokenService internal.RefreshTokenService
+	UserTokenService    internal.UserTokenService
+	MFAService          internal.MFAService
+	APIKeyService       internal.APIKeyService
+	IdentityService     internal.IdentityService
+	SessionService      internal.SessionService
+	AuditLogService     internal.AuditLogService
 }
 
 func NewMain() *Main {
@@ -131,9 +146,11 @@ func NewMain() *Main {
 		migrations.Up()
 
 		// Data Seeding
-		err = pgx.Seed(db, "internal/pgx/seeders/users.sql")
-		if err != nil {
-			return nil
+		for _, seeder := range []string{"users", "roles", "permissions"} {
+			err = pgx.Seed(db, "internal/pgx/seeders/"+seeder+".sql")
+			if err != nil {
+				return nil
+			}
 		}
 	}
 
@@ -144,25 +161,247 @@ func NewMain() *Main {
 		port = 8083 // Assign default port
 	}
 
+	// HTTP Server
+	httpServer := http.NewServer(port)
+	if secret := os.Getenv("APP_SECRET"); secret != "" {
+		httpServer.SigningSecret = []byte(secret)
+	} else {
+		internal.Warn("Main::NewMain", "Env doesn't have APP_SECRET, using random secret")
+	}
+	if ttl, err := time.ParseDuration(os.Getenv("ACCESS_TOKEN_TTL")); err == nil {
+		httpServer.AccessTokenTTL = ttl
+	}
+	if ttl, err := time.ParseDuration(os.Getenv("REFRESH_TOKEN_TTL")); err == nil {
+		httpServer.RefreshTokenTTL = ttl
+	}
+	keys, err := loadSigningKeys(httpServer.MaxTokenTTL())
+	if err != nil {
+		panic(err)
+	}
+	if keys != nil {
+		httpServer.Keys = keys
+	} else {
+		internal.Warn("Main::NewMain", "Env doesn't have JWT_PRIVATE_KEY_FILE or JWT_SECRET, using ephemeral signing key")
+	}
+	if appURL := os.Getenv("APP_URL"); appURL != "" {
+		httpServer.AppURL = strings.TrimSuffix(appURL, "/")
+	}
+	if issuer := os.Getenv("MFA_ISSUER"); issuer != "" {
+		httpServer.MFAIssuer = issuer
+	}
+	switch os.Getenv("MAILER") {
+	case "file":
+		httpServer.Mailer = mail.NewFileMailer(os.Getenv("MAIL_DIR"))
+	case "smtp":
+		httpServer.Mailer = mail.NewSMTPMailer(
+			os.Getenv("SMTP_HOST"),
+			os.Getenv("SMTP_PORT"),
+			os.Getenv("SMTP_USERNAME"),
+			os.Getenv("SMTP_PASSWORD"),
+			os.Getenv("MAIL_FROM"),
+		)
+	}
+	httpServer.OIDCProviders = loadOIDCProviders(port)
+	httpServer.Passwords = password.NewPolicy(loadPasswordHasher())
+
+	// CORS & cookie auth mode for browser frontends
+	if origins := os.Getenv("CORS_ALLOWED_ORIGINS"); origins != "" {
+		middlewares.Cors.AllowedOrigins = strings.Split(origins, ",")
+		for i, origin := range middlewares.Cors.AllowedOrigins {
+			middlewares.Cors.AllowedOrigins[i] = strings.TrimSpace(origin)
+		}
+	}
+	if allow, err := strconv.ParseBool(os.Getenv("CORS_ALLOW_CREDENTIALS")); err == nil {
+		middlewares.Cors.AllowCredentials = allow
+	}
+	httpServer.CookieAuth.Enabled, _ = strconv.ParseBool(os.Getenv("AUTH_COOKIES"))
+	httpServer.CookieAuth.Domain = os.Getenv("AUTH_COOKIE_DOMAIN")
+	if secure, err := strconv.ParseBool(os.Getenv("AUTH_COOKIE_SECURE")); err == nil {
+		httpServer.CookieAuth.Secure = secure
+	}
+	httpServer.CookieAuth.SameSite = http.ParseSameSite(os.Getenv("AUTH_COOKIE_SAMESITE"))
+	if httpServer.CookieAuth.Enabled && !middlewares.Cors.AllowCredentials {
+		internal.Warn("Main::NewMain", "AUTH_COOKIES is on but CORS_ALLOW_CREDENTIALS is off, cross-origin frontends won't send cookies")
+	}
+
 	// Create Main Object
 	return &Main{
 		DB:         db,
-		HTTPServer: http.NewServer(port),
+		HTTPServer: httpServer,
 		Port:       port,
 	}
 }
 
+// Loads JWT signing keys from env. The active key is read from PEM file
+// JWT_PRIVATE_KEY_FILE (RSA, ECDSA or Ed25519), else HMAC secret JWT_SECRET.
+// Keys in JWT_PREVIOUS_KEY_FILES (comma separated PEM files, private or
+// public) keep verifying tokens they signed until tokenTTL after the
+// rotation time JWT_ROTATED_AT (RFC 3339), when all such tokens have expired.
+// Returns nil if no active key is configured.
+func loadSigningKeys(tokenTTL time.Duration) (*http.KeySet, error) {
+	var active *http.SigningKey
+	if file := os.Getenv("JWT_PRIVATE_KEY_FILE"); file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if active, err = http.ParseSigningKeyPEM(data); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		if active.Private == nil {
+			return nil, fmt.Errorf("%s: not a private key", file)
+		}
+	} else if secret := os.Getenv("JWT_SECRET"); secret != "" {
+		active = http.NewHMACKey("hs256", []byte(secret))
+	} else {
+		return nil, nil
+	}
+	if kid := os.Getenv("JWT_KEY_ID"); kid != "" {
+		active.ID = kid
+	}
+
+	files := os.Getenv("JWT_PREVIOUS_KEY_FILES")
+	if strings.Trim(files, ", ") == "" {
+		return http.NewKeySet(active), nil
+	}
+	// Retirement is fixed by the rotation time, so restarts don't extend
+	// the lifetime of previous keys
+	rotatedAt, err := time.Parse(time.RFC3339, os.Getenv("JWT_ROTATED_AT"))
+	if err != nil {
+		return nil, fmt.Errorf("JWT_ROTATED_AT is required with JWT_PREVIOUS_KEY_FILES: %w", err)
+	}
+
+	var previous []*http.SigningKey
+	for _, file := range strings.Split(files, ",") {
+		if file = strings.TrimSpace(file); file == "" {
+			continue
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		key, err := http.ParseSigningKeyPEM(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		key.NotAfter = rotatedAt.Add(tokenTTL)
+		previous = append(previous, key)
+	}
+
+	return http.NewKeySet(active, previous...), nil
+}
+
+// Loads OpenID Connect providers from env. OIDC_PROVIDERS lists provider
+// names (comma separated), each configured by OIDC_<NAME>_ISSUER,
+// OIDC_<NAME>_CLIENT_ID, OIDC_<NAME>_CLIENT_SECRET, optional
+// OIDC_<NAME>_SCOPES (default "openid email profile") & OIDC_<NAME>_AUTO_PROVISION.
+// Callback URLs are based on API_URL, defaulting to localhost.
+func loadOIDCProviders(port int) map[string]*http.OIDCProvider {
+	apiURL := strings.TrimSuffix(os.Getenv("API_URL"), "/")
+	if apiURL == "" {
+		apiURL = "http://localhost:" + strconv.Itoa(port)
+	}
+
+	providers := make(map[string]*http.OIDCProvider)
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		env := func(key string) string {
+			return os.Getenv("OIDC_" + strings.ToUpper(name) + "_" + key)
+		}
+		if env("ISSUER") == "" || env("CLIENT_ID") == "" {
+			internal.Warn("Main::loadOIDCProviders", "Skipping OIDC provider "+name+" without ISSUER or CLIENT_ID")
+			continue
+		}
+		scopes := strings.Fields(env("SCOPES"))
+		if len(scopes) == 0 {
+			scopes = []string{"openid", "email", "profile"}
+		}
+		autoProvision, _ := strconv.ParseBool(env("AUTO_PROVISION"))
+
+		providers[name] = &http.OIDCProvider{
+			Provider: oidc.NewProvider(oidc.Config{
+				Issuer:       env("ISSUER"),
+				ClientID:     env("CLIENT_ID"),
+				ClientSecret: env("CLIENT_SECRET"),
+				RedirectURL:  apiURL + "/api/v1/auth/oidc/" + name + "/callback",
+				Scopes:       scopes,
+			}),
+			AutoProvision: autoProvision,
+		}
+	}
+	return providers
+}
+
+// Loads the hasher for new passwords from env. PASSWORD_HASHER selects
+// "bcrypt" (default, cost BCRYPT_COST) or "argon2id" (ARGON2_MEMORY in KiB,
+// ARGON2_ITERATIONS & ARGON2_PARALLELISM). Unset parameters take defaults.
+func loadPasswordHasher() password.Hasher {
+	switch hasher := os.Getenv("PASSWORD_HASHER"); hasher {
+	case "argon2id":
+		memory, _ := strconv.ParseUint(os.Getenv("ARGON2_MEMORY"), 10, 32)
+		iterations, _ := strconv.ParseUint(os.Getenv("ARGON2_ITERATIONS"), 10, 32)
+		parallelism, _ := strconv.ParseUint(os.Getenv("ARGON2_PARALLELISM"), 10, 8)
+		return password.Argon2idHasher{
+			Memory:      uint32(memory),
+			Iterations:  uint32(iterations),
+			Parallelism: uint8(parallelism),
+		}
+	case "", "bcrypt":
+	default:
+		internal.Warn("Main::loadPasswordHasher", "Unknown PASSWORD_HASHER "+hasher+", using bcrypt")
+	}
+
+	cost, _ := strconv.Atoi(os.Getenv("BCRYPT_COST"))
+	if cost != 0 && (cost < password.BcryptMinCost || cost > password.BcryptMaxCost) {
+		internal.Warn("Main::loadPasswordHasher", "BCRYPT_COST out of range, using default cost")
+		cost = 0
+	}
+	return password.BcryptHasher{Cost: cost}
+}
+
 // Run executes the main program
 func (main *Main) Run(ctx context.Context) (err error) {
 
 	// Initiate Services
 	userService := pgx.NewUserService(main.DB)
-
-	// Attach user service to Main for testing.
+	roleService := pgx.NewRoleService(main.DB)
+	refreshTokenService := pgx.NewRefreshTokenService(main.DB)
+	userTokenService := pgx.NewUserTokenService(main.DB)
+	mfaService := pgx.NewMFAService(main.DB)
+	apiKeyService := pgx.NewAPIKeyService(main.DB)
+	identityService := pgx.NewIdentityService(main.DB)
+	sessionService := pgx.NewSessionService(main.DB)
+	auditLogService := pgx.NewAuditLogService(main.DB)
+
+	// Attach services to Main for testing.
 	main.UserService = userService
+	main.RoleService = roleService
+	main.RefreshTokenService = refreshTokenService
+	main.UserTokenService = userTokenService
+	main.MFAService = mfaService
+	main.APIKeyService = apiKeyService
+	main.IdentityService = identityService
+	main.SessionService = sessionService
+	main.AuditLogService = auditLogService
 
 	// Attach underlying services to the HTTP server.
 	main.HTTPServer.UserService = userService
+	main.HTTPServer.RoleService = roleService
+	main.HTTPServer.RefreshTokenService = refreshTokenService
+	main.HTTPServer.UserTokenService = userTokenService
+	main.HTTPServer.MFAService = mfaService
+	main.HTTPServer.APIKeyService = apiKeyService
+	main.HTTPServer.IdentityService = identityService
+	main.HTTPServer.SessionService = sessionService
+	main.HTTPServer.AuditLogService = auditLogService
+
+	// Token revocations are kept in Postgres, so they are shared across
+	// instances & survive restarts, unless configured to stay in memory.
+	if os.Getenv("TOKEN_REVOCATION_STORE") != "memory" {
+		main.HTTPServer.TokenRevocationStore = pgx.NewTokenRevocationStore(main.DB)
+	}
 
 	// Start Server
 	go func() { main.HTTPServer.ListenAndServe() }()
@@ -0,0 +1,42 @@
+package internal
+
+import "time"
+
+// Represents personal API Key of a User, for service-to-service access.
+// Keys look like `kgo_<prefix>_<secret>`; the prefix identifies the key &
+// only the SHA-256 hash of the whole key is stored.
+type APIKey struct {
+	ID         uint       `db:"id" json:"id" example:"1"`                                          // API Key's ID
+	UserID     uint       `db:"user_id" json:"userId" example:"1"`                                 // Owner User's ID
+	Name       string     `db:"name" json:"name" example:"CRM Sync"`                               // API Key's name
+	Prefix     string     `db:"prefix" json:"prefix" example:"3f9a1c2b7d4e"`                       // Public part identifying the key
+	KeyHash    string     `db:"key_hash" json:"-"`                                                 // Hex encoded SHA-256 of the key, never serialized
+	Scopes     []string   `db:"scopes" json:"scopes" example:"['users:read']"`                     // Permissions the key is limited to, all of owner's if empty
+	ExpiresAt  *time.Time `db:"expires_at" json:"expiresAt" example:"2025-05-03T15:34:26.460Z"`    // Expiry Time, never expires if null
+	LastUsedAt *time.Time `db:"last_used_at" json:"lastUsedAt" example:"2024-05-03T15:34:26.460Z"` // Last Authentication Time
+	CreatedAt  time.Time  `db:"created_at" json:"createdAt" example:"2024-05-03T15:34:26.460Z"`    // API Key's Creation Time
+}
+
+// Expired checks if the key is past its expiry.
+func (k *APIKey) Expired() bool {
+	return k.ExpiresAt != nil && time.Now().After(*k.ExpiresAt)
+}
+
+// APIKeyService represents a service for managing API Keys.
+type APIKeyService interface {
+	// Retrieves an API Key by prefix. Returns ErrNotFound if key does not exist.
+	FindAPIKeyByPrefix(prefix string) (*APIKey, error)
+
+	// Retrieves API Keys of the user, newest first.
+	FindUserAPIKeys(userID int) ([]*APIKey, error)
+
+	// Creates a new API Key. Sets ID & creation time on the passed key.
+	CreateAPIKey(k *APIKey) error
+
+	// Records use of the API Key.
+	TouchAPIKey(id int) error
+
+	// Deletes API Key of the user. Returns ErrNotFound if the user has no
+	// such key.
+	DeleteAPIKey(userID int, id int) error
+}
@@ -0,0 +1,29 @@
+package internal
+
+import "time"
+
+// Actions recorded in the audit log
+const (
+	AuditActionImpersonate         = "impersonate"          // Impersonation started
+	AuditActionImpersonatedRequest = "impersonated_request" // Request made while impersonating
+)
+
+// Represents an audit log entry of an action taken by a User, kept even
+// after the users involved are deleted.
+type AuditLog struct {
+	ID        uint      `db:"id"`
+	ActorID   uint      `db:"actor_id"`   // User who took the action
+	SubjectID uint      `db:"subject_id"` // User the action was taken as or on
+	Action    string    `db:"action"`
+	Method    string    `db:"method"` // HTTP Method of the request
+	Path      string    `db:"path"`   // URL Path of the request
+	Status    int       `db:"status"` // HTTP Status of the response
+	IPAddress string    `db:"ip_address"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// AuditLogService represents a service for recording audit logs.
+type AuditLogService interface {
+	// Records a new audit log entry. Sets ID & creation time on the passed entry.
+	CreateAuditLog(l *AuditLog) error
+}
@@ -12,6 +12,15 @@ type contextKey int
 const (
 	// Stores the current logged in user in the context.
 	userContextKey = contextKey(iota + 1)
+
+	// Stores the API Key the request is authenticated with, if any.
+	apiKeyContextKey
+
+	// Stores the session of the Access Token the request is authenticated with.
+	sessionContextKey
+
+	// Stores the user impersonating the current user, if any.
+	actorContextKey
 )
 
 // Returns a new context with the given user.
@@ -33,3 +42,47 @@ func UserIDFromContext(ctx context.Context) uint {
 	}
 	return 0
 }
+
+// Returns a new context with the given API Key.
+func NewContextWithAPIKey(ctx context.Context, key *APIKey) context.Context {
+	return context.WithValue(ctx, apiKeyContextKey, key)
+}
+
+// APIKeyFromContext returns the API Key the request is authenticated with.
+// Returns nil if authenticated with an Access Token.
+func APIKeyFromContext(ctx context.Context) *APIKey {
+	key, _ := ctx.Value(apiKeyContextKey).(*APIKey)
+	return key
+}
+
+// Returns a new context with the given session.
+func NewContextWithSession(ctx context.Context, session *Session) context.Context {
+	return context.WithValue(ctx, sessionContextKey, session)
+}
+
+// SessionFromContext returns the session the request is made from.
+// Returns nil if authenticated with an API Key.
+func SessionFromContext(ctx context.Context) *Session {
+	session, _ := ctx.Value(sessionContextKey).(*Session)
+	return session
+}
+
+// Returns a new context with the given user impersonating the current user.
+func NewContextWithActor(ctx context.Context, actor *User) context.Context {
+	return context.WithValue(ctx, actorContextKey, actor)
+}
+
+// ActorFromContext returns the user really making the request: the
+// impersonator if the current user is impersonated, else the current user.
+func ActorFromContext(ctx context.Context) *User {
+	if actor, _ := ctx.Value(actorContextKey).(*User); actor != nil {
+		return actor
+	}
+	return UserFromContext(ctx)
+}
+
+// IsImpersonated checks if the current user is impersonated by another user.
+func IsImpersonated(ctx context.Context) bool {
+	actor, _ := ctx.Value(actorContextKey).(*User)
+	return actor != nil
+}
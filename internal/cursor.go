@@ -0,0 +1,11 @@
+package internal
+
+// Cursor marks a position in a keyset (cursor) paginated listing. It holds
+// the ORDER BY column values of the last row of the previous page, so the
+// next page continues right after it even while rows are being inserted.
+//
+// Cursors are handed to clients as opaque signed strings by the HTTP layer.
+type Cursor struct {
+	Sort   string `json:"s"` // Sort the cursor was issued for, see FormatSort()
+	Values []any  `json:"v"` // ORDER BY values of the last row, ID being last
+}
@@ -0,0 +1,19 @@
+package internal
+
+import "errors"
+
+// Application errors returned by the services. HTTP handlers check for these
+// with errors.Is() to pick the matching response status code.
+var (
+	// Requested record does not exist (or is soft deleted).
+	ErrNotFound = errors.New("record not found")
+
+	// Record conflicts with an existing one, e.g. duplicate unique email.
+	ErrConflict = errors.New("record already exists")
+
+	// Pagination cursor is malformed or doesn't match the listing.
+	ErrInvalidCursor = errors.New("invalid cursor")
+
+	// Already used token was presented again, e.g. a stolen refresh token.
+	ErrTokenReused = errors.New("token reused")
+)
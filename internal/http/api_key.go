@@ -0,0 +1,264 @@
+package http
+
+import (
+	"go-api/internal"
+	"go-api/internal/http/middlewares"
+
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Prefix of API Keys, making them recognizable e.g. by secret scanners
+const apiKeyPrefix = "kgo_"
+
+// Helper function for registering all API Key routes.
+func (s *Server) registerAPIKeyRoutes(r *http.ServeMux) {
+	sm := http.NewServeMux()
+
+	// Module Middlewares
+	stack := middlewares.CreateStack(
+		middlewares.Logging,
+		middlewares.RateLimiter,
+		middlewares.AllowCors,
+		s.IsAuthenticated,
+		requireAccessToken,
+		forbidImpersonation,
+	)
+
+	sm.HandleFunc("GET /", s.APIKeyAll)
+	sm.HandleFunc("POST /", s.APIKeyCreate)
+	sm.HandleFunc("DELETE /{id}", s.APIKeyDeleteByID)
+
+	r.Handle("/api/v1/api-keys/", stack(http.StripPrefix("/api/v1/api-keys", sm)))
+}
+
+// Rejects requests authenticated with an API Key, so a scoped key can't
+// mint keys with wider scopes, get a session or change credentials.
+func requireAccessToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if internal.APIKeyFromContext(r.Context()) != nil {
+			internal.APIError(w, "Http::requireAccessToken", "Not allowed with API Key, use Access Token", http.StatusForbidden, nil)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// APIKeyAll godoc
+//
+//	@Summary		Fetch own API Keys
+//	@Description	Fetch API Keys of the signed in User, newest first
+//	@Tags			api-keys
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	[]internal.APIKey
+//	@Failure		401	{object}	internal.ErrorResponse	"Invalid Bearer Token"
+//	@Failure		500	{object}	internal.ErrorResponse	"Server error"
+//	@Router			/api/v1/api-keys [get]
+//	@Security		Bearer
+func (s *Server) APIKeyAll(w http.ResponseWriter, r *http.Request) {
+	keys, err := s.APIKeyService.FindUserAPIKeys(int(internal.UserIDFromContext(r.Context())))
+	if err != nil {
+		internal.APIError(w, "Http::APIKeyAll", "Couldn't find API Keys", http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, "Http::APIKeyAll", http.StatusOK, keys)
+}
+
+// Represents API Key Create Request
+type APIKeyCreateRequest struct {
+	Name      string     `json:"name" example:"CRM Sync"`                      // API Key's name
+	Scopes    []string   `json:"scopes" example:"['users:read']"`              // Optional permissions to limit the key to
+	ExpiresAt *time.Time `json:"expiresAt" example:"2025-05-03T15:34:26.460Z"` // Optional Expiry Time
+}
+
+// Validate checks fields of the create request.
+func (req APIKeyCreateRequest) Validate() error {
+	if strings.TrimSpace(req.Name) == "" {
+		return errors.New("name is required")
+	}
+	for _, scope := range req.Scopes {
+		if !internal.IsPermission(scope) {
+			return fmt.Errorf("unknown scope %q", scope)
+		}
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return errors.New("expiresAt must be in the future")
+	}
+	return nil
+}
+
+// Represents API Key Create Response, the only time the key is shown
+type APIKeyCreateResponse struct {
+	internal.APIKey
+	Key string `json:"key" example:"kgo_3f9a1c2b7d4e_kKxVbHn0Ry0Ku2pV5Wm1Yf3b2c5ZV7H9HnRkq1xTzJQ"` // The API Key, shown only once
+}
+
+// APIKeyCreate godoc
+//
+//	@Summary		Create API Key
+//	@Description	Create API Key for the signed in User. The key is only returned in this response. Send it in `X-API-Key` header or as `Authorization: ApiKey <key>`.
+//	@Tags			api-keys
+//	@Accept			json
+//	@Param			input	body	APIKeyCreateRequest	true	"API Key Details"
+//	@Produce		json
+//	@Success		201	{object}	APIKeyCreateResponse
+//	@Failure		400	{object}	internal.ErrorResponse	"Invalid JSON body"
+//	@Failure		401	{object}	internal.ErrorResponse	"Invalid Bearer Token"
+//	@Failure		403	{object}	internal.ErrorResponse	"Authenticated with API Key"
+//	@Failure		500	{object}	internal.ErrorResponse	"Server error"
+//	@Router			/api/v1/api-keys [post]
+//	@Security		Bearer
+func (s *Server) APIKeyCreate(w http.ResponseWriter, r *http.Request) {
+	var req APIKeyCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		internal.APIError(w, "Http::APIKeyCreate", "Invalid JSON body", http.StatusBadRequest, err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		internal.APIError(w, "Http::APIKeyCreate", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	key, prefix, err := generateAPIKey()
+	if err != nil {
+		internal.APIError(w, "Http::APIKeyCreate", "Failed to generate API Key", http.StatusInternalServerError, err)
+		return
+	}
+
+	apiKey := internal.APIKey{
+		UserID:    internal.UserIDFromContext(r.Context()),
+		Name:      strings.TrimSpace(req.Name),
+		Prefix:    prefix,
+		KeyHash:   hashToken(key),
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+	}
+	if err := s.APIKeyService.CreateAPIKey(&apiKey); err != nil {
+		internal.APIError(w, "Http::APIKeyCreate", "Failed to create API Key", http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/api-keys/"+strconv.Itoa(int(apiKey.ID)))
+	writeJSON(w, "Http::APIKeyCreate", http.StatusCreated, APIKeyCreateResponse{APIKey: apiKey, Key: key})
+}
+
+// APIKeyDeleteByID godoc
+//
+//	@Summary		Revoke API Key by ID
+//	@Description	Revoke API Key of the signed in User by ID
+//	@Tags			api-keys
+//	@Accept			json
+//	@Param			id	path	integer	true	"API Key ID"	default(1)
+//	@Produce		json
+//	@Success		204
+//	@Failure		401	{object}	internal.ErrorResponse	"Invalid Bearer Token"
+//	@Failure		403	{object}	internal.ErrorResponse	"Authenticated with API Key"
+//	@Failure		404	{object}	internal.ErrorResponse	"API Key not found"
+//	@Failure		500	{object}	internal.ErrorResponse	"Server error"
+//	@Router			/api/v1/api-keys/{id} [delete]
+//	@Security		Bearer
+func (s *Server) APIKeyDeleteByID(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		internal.APIError(w, "Http::APIKeyDeleteByID", "Invalid API Key ID", http.StatusNotFound, err)
+		return
+	}
+
+	if err := s.APIKeyService.DeleteAPIKey(int(internal.UserIDFromContext(r.Context())), id); err != nil {
+		internal.APIError(w, "Http::APIKeyDeleteByID", "API Key not found", errorStatus(err), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Pulls API Key out of `X-API-Key` header or `Authorization: ApiKey` scheme.
+func apiKeyFromRequest(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	if key, ok := strings.CutPrefix(r.Header.Get("Authorization"), "ApiKey "); ok {
+		return strings.TrimSpace(key)
+	}
+	return ""
+}
+
+// Authenticates the request with the API Key & serves next with its owner
+// in context. Permissions of the owner are limited to the key scopes.
+func (s *Server) authenticateAPIKey(w http.ResponseWriter, r *http.Request, key string, next http.Handler) {
+	prefix, ok := parseAPIKey(key)
+	if !ok {
+		internal.APIError(w, "Http::IsAuthenticated", "Invalid API Key", http.StatusUnauthorized, nil)
+		return
+	}
+
+	apiKey, err := s.APIKeyService.FindAPIKeyByPrefix(prefix)
+	if err != nil {
+		if errors.Is(err, internal.ErrNotFound) {
+			internal.APIError(w, "Http::IsAuthenticated", "Invalid API Key", http.StatusUnauthorized, err)
+			return
+		}
+		internal.APIError(w, "Http::IsAuthenticated", "Couldn't check API Key", http.StatusInternalServerError, err)
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(apiKey.KeyHash), []byte(hashToken(key))) != 1 {
+		internal.APIError(w, "Http::IsAuthenticated", "Invalid API Key", http.StatusUnauthorized, nil)
+		return
+	}
+	if apiKey.Expired() {
+		internal.APIError(w, "Http::IsAuthenticated", "API Key Expired", http.StatusUnauthorized, nil)
+		return
+	}
+
+	user, err := s.UserService.FindUserByID(int(apiKey.UserID))
+	if err != nil {
+		internal.APIError(w, "Http::IsAuthenticated", "API Key User not found", http.StatusUnauthorized, err)
+		return
+	}
+	if len(apiKey.Scopes) > 0 {
+		user.Permissions = internal.IntersectPermissions(user.Permissions, apiKey.Scopes)
+	}
+
+	if err := s.APIKeyService.TouchAPIKey(int(apiKey.ID)); err != nil {
+		internal.Error("Http::IsAuthenticated", "Couldn't record API Key use", err)
+	}
+
+	ctx := internal.NewContextWithUser(r.Context(), user)
+	ctx = internal.NewContextWithAPIKey(ctx, apiKey)
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// Generates a new API Key, returning it along with its prefix.
+func generateAPIKey() (string, string, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	prefix := hex.EncodeToString(b)
+
+	secret, err := generateOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+	return apiKeyPrefix + prefix + "_" + secret, prefix, nil
+}
+
+// Extracts prefix of the API Key. Returns false if key is malformed.
+func parseAPIKey(key string) (string, bool) {
+	rest, ok := strings.CutPrefix(key, apiKeyPrefix)
+	if !ok {
+		return "", false
+	}
+	prefix, secret, ok := strings.Cut(rest, "_")
+	return prefix, ok && len(prefix) == 12 && secret != ""
+}
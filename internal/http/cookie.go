@@ -0,0 +1,137 @@
+package http
+
+import (
+	"go-api/internal"
+
+	"crypto/hmac"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	accessTokenCookie  = "access_token"  // HttpOnly cookie carrying the Access Token
+	refreshTokenCookie = "refresh_token" // HttpOnly cookie carrying the Refresh Token
+	csrfTokenCookie    = "csrf_token"    // Cookie readable by the frontend, to be echoed in csrfTokenHeader
+	csrfTokenHeader    = "X-CSRF-Token"
+
+	refreshTokenCookiePath = "/api/v1/auth/" // Refresh Token is only sent to refresh & signout
+)
+
+// CookieAuthConfig configures the cookie auth mode for browser frontends.
+// Signin responses set the tokens as HttpOnly cookies instead of returning
+// them, IsAuthenticated accepts the Access Token cookie, and state-changing
+// requests authenticated by cookie need the CSRF token from csrf_token
+// cookie in X-CSRF-Token header (double-submit).
+type CookieAuthConfig struct {
+	Enabled  bool
+	Domain   string        // Cookie domain, host of the API if empty
+	Secure   bool          // Send cookies over HTTPS only
+	SameSite http.SameSite // SameSite mode of the cookies
+}
+
+// ParseSameSite returns the SameSite mode named "strict", "none" or "lax",
+// defaulting to lax.
+func ParseSameSite(mode string) http.SameSite {
+	switch strings.ToLower(mode) {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	}
+	return http.SameSiteLaxMode
+}
+
+// Sets Access, Refresh & CSRF token cookies for the session.
+func (s *Server) setAuthCookies(w http.ResponseWriter, accessToken string, refreshToken string, refresh *internal.RefreshToken) {
+	http.SetCookie(w, s.authCookie(accessTokenCookie, accessToken, "/", s.AccessTokenTTL, true))
+	http.SetCookie(w, s.authCookie(refreshTokenCookie, refreshToken, refreshTokenCookiePath, time.Until(refresh.ExpiresAt), true))
+	http.SetCookie(w, s.authCookie(csrfTokenCookie, s.csrfToken(refresh.FamilyID), "/", time.Until(refresh.ExpiresAt), false))
+}
+
+// Expires the auth cookies.
+func (s *Server) clearAuthCookies(w http.ResponseWriter) {
+	http.SetCookie(w, s.authCookie(accessTokenCookie, "", "/", -1, true))
+	http.SetCookie(w, s.authCookie(refreshTokenCookie, "", refreshTokenCookiePath, -1, true))
+	http.SetCookie(w, s.authCookie(csrfTokenCookie, "", "/", -1, false))
+}
+
+func (s *Server) authCookie(name string, value string, path string, ttl time.Duration, httpOnly bool) *http.Cookie {
+	maxAge := int(ttl / time.Second)
+	if ttl < 0 {
+		maxAge = -1
+	}
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   s.CookieAuth.Domain,
+		MaxAge:   maxAge,
+		Secure:   s.CookieAuth.Secure,
+		HttpOnly: httpOnly,
+		SameSite: s.CookieAuth.SameSite,
+	}
+}
+
+// Returns the Access Token of the request from Bearer header, else from the
+// cookie in cookie auth mode. fromCookie tells where it came from.
+func (s *Server) accessTokenFromRequest(r *http.Request) (token string, fromCookie bool) {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return token, false
+	}
+	if s.CookieAuth.Enabled {
+		if cookie, err := r.Cookie(accessTokenCookie); err == nil {
+			return cookie.Value, true
+		}
+	}
+	return "", false
+}
+
+// Returns the Refresh Token cookie in cookie auth mode, if any.
+func (s *Server) refreshTokenFromCookie(r *http.Request) string {
+	if !s.CookieAuth.Enabled {
+		return ""
+	}
+	cookie, err := r.Cookie(refreshTokenCookie)
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
+
+// CSRF token of the session. It's derived from the session ID with the
+// server secret, so it can't be forged by planting a cookie.
+func (s *Server) csrfToken(sessionID string) string {
+	return base64.RawURLEncoding.EncodeToString(signature(s.SigningSecret, signPurposeCSRF, sessionID))
+}
+
+// Checks the CSRF token of a cookie authenticated request: the header must
+// match the cookie & the token of the session. Safe methods need none.
+func (s *Server) validCSRF(r *http.Request, sessionID string) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	header := r.Header.Get(csrfTokenHeader)
+	cookie, err := r.Cookie(csrfTokenCookie)
+	if err != nil || header == "" || !hmac.Equal([]byte(header), []byte(cookie.Value)) {
+		return false
+	}
+	return hmac.Equal([]byte(header), []byte(s.csrfToken(sessionID)))
+}
+
+// Checks the CSRF token of a request presenting the Refresh Token cookie,
+// against the session of the token. Unknown tokens pass, to be rejected
+// when used.
+func (s *Server) validRefreshCSRF(r *http.Request, refreshToken string) (bool, error) {
+	t, err := s.RefreshTokenService.FindRefreshToken(hashToken(refreshToken))
+	if errors.Is(err, internal.ErrNotFound) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return s.validCSRF(r, t.FamilyID), nil
+}
@@ -1 +1,50 @@
 package http
+
+import (
+	"go-api/internal"
+	"go-api/internal/http/middlewares"
+
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// Maps application errors to HTTP status codes.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, internal.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, internal.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, internal.ErrInvalidCursor):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Decodes a generic JSON value into dst, rejecting fields unknown to dst.
+func decodeStrict(v any, dst any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode(dst)
+}
+
+// Writes v as JSON response with the given status code.
+func writeJSON(w http.ResponseWriter, module string, statusCode int, v any) {
+	w.Header().Set("Content-type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		internal.Error(module, "Issue with Data Parsing", err)
+	}
+}
+
+// Wraps handler to allow only users having the permission.
+func can(permission string, handler http.HandlerFunc) http.Handler {
+	return middlewares.RequirePermission(permission)(handler)
+}
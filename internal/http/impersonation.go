@@ -0,0 +1,170 @@
+package http
+
+import (
+	"go-api/internal"
+
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// Helper function for registering impersonation routes on the auth module mux.
+func (s *Server) registerImpersonationRoutes(sm *http.ServeMux) {
+	sm.Handle("POST /impersonate/{userId}", s.IsAuthenticated(requireAccessToken(forbidImpersonation(
+		can(internal.PermissionUsersImpersonate, s.Impersonate),
+	))))
+}
+
+// Represents Impersonation Response
+type ImpersonationResponse struct {
+	ID        uint     `json:"id" example:"2"`                                                        // Impersonated User's ID
+	Name      string   `json:"name" example:"Ganesh Bhosale"`                                         // Impersonated User's Name
+	Email     string   `json:"email" example:"ganesh@dwij.in"`                                        // Impersonated User's Email
+	Roles     []string `json:"roles" example:"['Customer']"`                                          // Impersonated User's Roles
+	ActorID   uint     `json:"actorId" example:"1"`                                                   // Impersonator's ID
+	Token     string   `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9.e30.c2lnbmF0dXJl"` // Access Token acting as the User
+	ExpiresAt string   `json:"expiresAt" example:"2024-05-03T15:34:26.460Z"`                          // Access Token Expiry Time
+}
+
+// Impersonate godoc
+//
+//	@Summary		Impersonate User
+//	@Description	Issues an Access Token acting as the User, for seeing the app exactly as the User does. The token carries the impersonator in `act` claim, belongs to the impersonator's session & can't be refreshed. Only users having all permissions of the User can impersonate, and every request made with the token is audited.
+//	@Tags			auth
+//	@Param			userId	path	integer	true	"User ID"	default(2)
+//	@Produce		json
+//	@Success		200	{object}	ImpersonationResponse
+//	@Failure		400	{object}	internal.ErrorResponse	"Can't impersonate yourself"
+//	@Failure		401	{object}	internal.ErrorResponse	"Invalid Bearer Token"
+//	@Failure		403	{object}	internal.ErrorResponse	"Missing permission or User has more permissions"
+//	@Failure		404	{object}	internal.ErrorResponse	"User not found"
+//	@Failure		500	{object}	internal.ErrorResponse	"Server error"
+//	@Router			/api/v1/auth/impersonate/{userId} [post]
+//	@Security		Bearer
+func (s *Server) Impersonate(w http.ResponseWriter, r *http.Request) {
+	actor := internal.ActorFromContext(r.Context())
+
+	id, err := strconv.Atoi(r.PathValue("userId"))
+	if err != nil {
+		internal.APIError(w, "Http::Impersonate", "User not found", http.StatusNotFound, err)
+		return
+	}
+	if id == int(actor.ID) {
+		internal.APIError(w, "Http::Impersonate", "Can't impersonate yourself", http.StatusBadRequest, nil)
+		return
+	}
+
+	subject, err := s.UserService.FindUserByID(id)
+	if err != nil {
+		internal.APIError(w, "Http::Impersonate", "User not found", errorStatus(err), err)
+		return
+	}
+
+	// Impersonation must not grant permissions the actor doesn't have
+	if !actor.HasPermissionsOf(subject) {
+		internal.APIError(w, "Http::Impersonate", "User has permissions you don't have", http.StatusForbidden, nil)
+		return
+	}
+
+	expiresAtTime := time.Now().Add(s.AccessTokenTTL)
+	token, err := s.generateImpersonationToken(actor, subject, internal.SessionFromContext(r.Context()).ID, expiresAtTime)
+	if err != nil {
+		internal.APIError(w, "Http::Impersonate", "Failed to generate access token", http.StatusInternalServerError, err)
+		return
+	}
+
+	s.audit(r, internal.AuditLog{
+		ActorID:   actor.ID,
+		SubjectID: subject.ID,
+		Action:    internal.AuditActionImpersonate,
+		Status:    http.StatusOK,
+	})
+
+	writeJSON(w, "Http::Impersonate", http.StatusOK, ImpersonationResponse{
+		ID:        subject.ID,
+		Name:      subject.Name,
+		Email:     subject.Email,
+		Roles:     subject.Roles,
+		ActorID:   actor.ID,
+		Token:     token,
+		ExpiresAt: expiresAtTime.UTC().Format("2006-01-02T15:04:05.000Z"),
+	})
+}
+
+// Generate JWT Access Token acting as subject, with the actor in `act`
+// claim (RFC 8693). Token belongs to the session of the actor, so ends
+// with it.
+func (s *Server) generateImpersonationToken(actor *internal.User, subject *internal.User, sessionID string, expiresAtTime time.Time) (string, error) {
+	jti, err := generateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	return s.Keys.Sign(jwt.MapClaims{
+		"jti":   jti,
+		"id":    subject.ID,
+		"sid":   sessionID,
+		"act":   map[string]any{"sub": actor.ID},
+		"roles": subject.Roles,
+		"iat":   time.Now().Unix(),
+		"exp":   expiresAtTime.Unix(),
+	})
+}
+
+// Returns ID of the impersonator from `act` claim, or zero if the token
+// isn't an impersonation token.
+func actorID(claims jwt.MapClaims) int {
+	act, _ := claims["act"].(map[string]any)
+	sub, _ := act["sub"].(float64)
+	return int(sub)
+}
+
+// Rejects impersonated requests, for routes managing the user's own
+// credentials & sessions.
+func forbidImpersonation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if internal.IsImpersonated(r.Context()) {
+			internal.APIError(w, "Http::forbidImpersonation", "Not allowed while impersonating", http.StatusForbidden, nil)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Serves the impersonated request & records it in the audit log.
+func (s *Server) serveImpersonated(w http.ResponseWriter, r *http.Request, next http.Handler) {
+	recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	next.ServeHTTP(recorder, r)
+
+	s.audit(r, internal.AuditLog{
+		ActorID:   internal.ActorFromContext(r.Context()).ID,
+		SubjectID: internal.UserIDFromContext(r.Context()),
+		Action:    internal.AuditActionImpersonatedRequest,
+		Status:    recorder.status,
+	})
+}
+
+// Records audit log entry for the request. Failures are logged only, as
+// the response is already decided.
+func (s *Server) audit(r *http.Request, entry internal.AuditLog) {
+	// Path before any StripPrefix of the module mux, without the query
+	entry.Method = r.Method
+	entry.Path, _, _ = strings.Cut(r.RequestURI, "?")
+	entry.IPAddress = clientIP(r)
+	if err := s.AuditLogService.CreateAuditLog(&entry); err != nil {
+		internal.Error("Http::audit", "Couldn't record audit log", err)
+	}
+}
+
+// Records status code written by the handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusRecorder) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
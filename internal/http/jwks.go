@@ -0,0 +1,40 @@
+package http
+
+import (
+	"go-api/internal"
+	"go-api/internal/http/middlewares"
+
+	"net/http"
+)
+
+// Helper function for registering the JWKS route.
+func (s *Server) registerJWKSRoutes(r *http.ServeMux) {
+	// Module Middlewares
+	stack := middlewares.CreateStack(
+		middlewares.Logging,
+		middlewares.RateLimiter,
+		middlewares.AllowCors,
+	)
+
+	r.Handle("/.well-known/jwks.json", stack(http.HandlerFunc(s.JWKS)))
+}
+
+// JWKS godoc
+//
+//	@Summary		JSON Web Key Set
+//	@Description	Public keys for verifying Access Tokens, matched by the `kid` token header. Includes previous keys until they retire.
+//	@Tags			auth
+//	@Produce		json
+//	@Success		200	{object}	JWKSet
+//	@Router			/.well-known/jwks.json [get]
+func (s *Server) JWKS(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		internal.APIError(w, "Http::JWKS", "Method not allowed", http.StatusMethodNotAllowed, nil)
+		return
+	}
+
+	// Let verifiers cache the keys for a while
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	writeJSON(w, "Http::JWKS", http.StatusOK, s.Keys.JWKS())
+}
@@ -0,0 +1,280 @@
+package http
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// SigningKey is a key used to sign or verify JWT Access Tokens. It is
+// identified by its ID, which is sent as `kid` header of the token.
+type SigningKey struct {
+	ID       string            // Key ID, defaults to RFC 7638 thumbprint of public key
+	Method   jwt.SigningMethod // Signing algorithm
+	Private  any               // Private key or HMAC secret, nil for verification only keys
+	Public   any               // Public key or HMAC secret used for verification
+	NotAfter time.Time         // Key stops verifying after this time, zero means no limit
+}
+
+// Creates signing key from a private key. RSA keys sign with RS256, ECDSA
+// keys with ES256/ES384/ES512 as per their curve and Ed25519 keys with EdDSA.
+func NewSigningKey(private crypto.Signer) (*SigningKey, error) {
+	key := &SigningKey{Private: private, Public: private.Public()}
+	switch k := private.(type) {
+	case *rsa.PrivateKey:
+		key.Method = jwt.SigningMethodRS256
+	case *ecdsa.PrivateKey:
+		method, err := ecdsaMethod(k.Curve)
+		if err != nil {
+			return nil, err
+		}
+		key.Method = method
+	case ed25519.PrivateKey:
+		key.Method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", private)
+	}
+
+	jwk, err := newJWK(key.Public)
+	if err != nil {
+		return nil, err
+	}
+	key.ID = jwk.Thumbprint()
+
+	return key, nil
+}
+
+// Creates verification only key from a public key.
+func NewVerificationKey(public crypto.PublicKey) (*SigningKey, error) {
+	jwk, err := newJWK(public)
+	if err != nil {
+		return nil, err
+	}
+	method := jwt.GetSigningMethod(jwk.Alg)
+	if method == nil {
+		return nil, fmt.Errorf("unsupported algorithm %s", jwk.Alg)
+	}
+	return &SigningKey{ID: jwk.Thumbprint(), Method: method, Public: public}, nil
+}
+
+// Creates HS256 signing key from shared secret. Such keys are never published in JWKS.
+func NewHMACKey(id string, secret []byte) *SigningKey {
+	return &SigningKey{ID: id, Method: jwt.SigningMethodHS256, Private: secret, Public: secret}
+}
+
+// Generates a new random Ed25519 signing key.
+func GenerateSigningKey() (*SigningKey, error) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return NewSigningKey(private)
+}
+
+// Parses PEM encoded private key (PKCS#8, PKCS#1 or SEC 1) into signing key,
+// or PKIX public key into verification only key.
+func ParseSigningKeyPEM(data []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var private any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		private, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		private, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		private, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		public, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return NewVerificationKey(public)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := private.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", private)
+	}
+	return NewSigningKey(signer)
+}
+
+func ecdsaMethod(curve elliptic.Curve) (jwt.SigningMethod, error) {
+	switch curve {
+	case elliptic.P256():
+		return jwt.SigningMethodES256, nil
+	case elliptic.P384():
+		return jwt.SigningMethodES384, nil
+	case elliptic.P521():
+		return jwt.SigningMethodES512, nil
+	}
+	return nil, fmt.Errorf("unsupported curve %s", curve.Params().Name)
+}
+
+// KeySet holds the active key used for signing new tokens, along with
+// previous keys which still verify tokens issued before a rotation, until
+// their NotAfter time.
+type KeySet struct {
+	mu     sync.RWMutex
+	active *SigningKey
+	keys   map[string]*SigningKey
+}
+
+// Creates key set signing with active key & verifying with all given keys.
+func NewKeySet(active *SigningKey, previous ...*SigningKey) *KeySet {
+	ks := &KeySet{active: active, keys: map[string]*SigningKey{active.ID: active}}
+	for _, key := range previous {
+		ks.keys[key.ID] = key
+	}
+	return ks
+}
+
+// Sign creates a token with given claims, signed by the active key.
+func (ks *KeySet) Sign(claims jwt.MapClaims) (string, error) {
+	ks.mu.RLock()
+	key := ks.active
+	ks.mu.RUnlock()
+
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.Private)
+}
+
+// Keyfunc returns key for verifying the token as per its `kid` header.
+// Tokens without `kid` are verified by the active key.
+func (ks *KeySet) Keyfunc(token *jwt.Token) (any, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	key := ks.active
+	if kid, ok := token.Header["kid"].(string); ok {
+		if key, ok = ks.keys[kid]; !ok {
+			return nil, fmt.Errorf("unknown key %q", kid)
+		}
+	}
+
+	// Algorithm must match the key, to prevent algorithm confusion
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("invalid signing method %s for key %q", token.Method.Alg(), key.ID)
+	}
+	if !key.NotAfter.IsZero() && time.Now().After(key.NotAfter) {
+		return nil, fmt.Errorf("key %q is retired", key.ID)
+	}
+
+	return key.Public, nil
+}
+
+// JWKS returns the public keys of the set. HMAC & retired keys are left out.
+func (ks *KeySet) JWKS() JWKSet {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	set := JWKSet{Keys: []JWK{}}
+	for _, key := range ks.keys {
+		if !key.NotAfter.IsZero() && time.Now().After(key.NotAfter) {
+			continue
+		}
+		jwk, err := newJWK(key.Public)
+		if err != nil {
+			continue // HMAC secret
+		}
+		jwk.Kid = key.ID
+		set.Keys = append(set.Keys, *jwk)
+	}
+	return set
+}
+
+// Represents JSON Web Key Set (RFC 7517)
+type JWKSet struct {
+	Keys []JWK `json:"keys"` // Public Keys
+}
+
+// Represents public JSON Web Key (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty" example:"OKP"`                                                   // Key Type
+	Kid string `json:"kid,omitempty" example:"NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"` // Key ID
+	Use string `json:"use,omitempty" example:"sig"`                                         // Public Key Use
+	Alg string `json:"alg,omitempty" example:"EdDSA"`                                       // Algorithm
+	Crv string `json:"crv,omitempty" example:"Ed25519"`                                     // Curve of EC & OKP keys
+	X   string `json:"x,omitempty" example:"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"`   // X coordinate of EC & OKP keys
+	Y   string `json:"y,omitempty"`                                                         // Y coordinate of EC keys
+	N   string `json:"n,omitempty"`                                                         // Modulus of RSA keys
+	E   string `json:"e,omitempty"`                                                         // Exponent of RSA keys
+}
+
+func newJWK(public any) (*JWK, error) {
+	b64 := base64.RawURLEncoding.EncodeToString
+	switch k := public.(type) {
+	case *rsa.PublicKey:
+		return &JWK{Kty: "RSA", Use: "sig", Alg: "RS256", N: b64(k.N.Bytes()), E: b64(big.NewInt(int64(k.E)).Bytes())}, nil
+	case *ecdsa.PublicKey:
+		method, err := ecdsaMethod(k.Curve)
+		if err != nil {
+			return nil, err
+		}
+		size := (k.Curve.Params().BitSize + 7) / 8
+		return &JWK{
+			Kty: "EC", Use: "sig", Alg: method.Alg(), Crv: k.Curve.Params().Name,
+			X: b64(k.X.FillBytes(make([]byte, size))),
+			Y: b64(k.Y.FillBytes(make([]byte, size))),
+		}, nil
+	case ed25519.PublicKey:
+		return &JWK{Kty: "OKP", Use: "sig", Alg: "EdDSA", Crv: "Ed25519", X: b64(k)}, nil
+	}
+	return nil, fmt.Errorf("unsupported public key type %T", public)
+}
+
+// Thumbprint returns RFC 7638 SHA-256 thumbprint of the key.
+func (k JWK) Thumbprint() string {
+	// Required members only, in lexicographic order
+	var members any
+	switch k.Kty {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{k.E, k.Kty, k.N}
+	case "EC":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		}{k.Crv, k.Kty, k.X, k.Y}
+	default:
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{k.Crv, k.Kty, k.X}
+	}
+
+	data, _ := json.Marshal(members)
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
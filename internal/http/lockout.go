@@ -0,0 +1,84 @@
+package http
+
+import (
+	"go-api/internal"
+
+	"crypto/rand"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Signin lockout policy. Once failed signins reach signinMaxAttempts, the
+// account is locked for signinLockoutBase, doubling with every further
+// failure up to signinLockoutMax.
+const (
+	signinMaxAttempts = 5
+	signinLockoutBase = time.Minute
+	signinLockoutMax  = time.Hour
+)
+
+// Returns lock duration after given number of consecutive failed signins,
+// zero if account shouldn't be locked yet.
+func signinLockout(attempts int) time.Duration {
+	if attempts < signinMaxAttempts {
+		return 0
+	}
+	exp := float64(attempts - signinMaxAttempts)
+	return time.Duration(min(float64(signinLockoutBase)*math.Pow(2, exp), float64(signinLockoutMax)))
+}
+
+// Hash of a random password, compared against for unknown emails so they
+// take as long as wrong passwords. It's made with the password policy, so
+// it costs the same as hashes of real users.
+func (s *Server) dummyPasswordHash() string {
+	s.dummyHashOnce.Do(func() {
+		password := make([]byte, 16)
+		rand.Read(password)
+		s.dummyHash, _ = s.Passwords.Hash(string(password))
+	})
+	return s.dummyHash
+}
+
+// Counts failed signin of the user & locks the account once the limit is
+// reached. Returns true if the account got locked. Failures are only
+// logged, as signin is rejected anyway.
+func (s *Server) recordFailedSignin(user *internal.User) bool {
+	attempts, err := s.UserService.RecordFailedSignin(int(user.ID))
+	if err != nil {
+		internal.Error("Http::Signin", "Couldn't record failed signin", err)
+		return false
+	}
+	lockout := signinLockout(attempts)
+	if lockout == 0 {
+		return false
+	}
+	if err := s.UserService.LockUser(int(user.ID), time.Now().Add(lockout)); err != nil {
+		internal.Error("Http::Signin", "Couldn't lock user", err)
+		return false
+	}
+	return true
+}
+
+// Clears failed signin count & lock of the user after a successful signin.
+func (s *Server) resetFailedSignins(module string, user *internal.User) {
+	if user.FailedSigninAttempts == 0 && user.LockedUntil == nil {
+		return
+	}
+	if err := s.UserService.UnlockUser(int(user.ID)); err != nil {
+		internal.Error(module, "Couldn't reset failed signins", err)
+	}
+}
+
+// Writes 429 response with Retry-After header if signin of the user is
+// locked. Returns true if a response was written.
+func rejectLocked(w http.ResponseWriter, module string, user *internal.User) bool {
+	if user.LockedUntil == nil || !time.Now().Before(*user.LockedUntil) {
+		return false
+	}
+	retryAfter := int(math.Ceil(time.Until(*user.LockedUntil).Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	internal.APIError(w, module, "Too many failed signins, account locked", http.StatusTooManyRequests, nil)
+	return true
+}
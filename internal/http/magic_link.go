@@ -0,0 +1,145 @@
+package http
+
+import (
+	"go-api/internal"
+
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Validity of magic signin links
+const magicLinkTTL = 15 * time.Minute
+
+// Represents Magic Link Request
+type MagicLinkRequest struct {
+	Email string `json:"email" example:"ganesh@dwij.in"` // Email Address of the account
+}
+
+// Represents Magic Link Response
+type MagicLinkResponse struct {
+	Message string `json:"message" example:"If the email is registered, a signin link has been sent to it"` // Result Message
+}
+
+// MagicLink godoc
+//
+//	@Summary		Magic Link Signin API
+//	@Description	Mails a single use signin link, valid for 15 minutes, if the email is registered. Response is the same either way, so it doesn't reveal registered emails.
+//	@Tags			auth
+//	@Accept			json
+//	@Param			input	body	MagicLinkRequest	true	"Account Email"
+//	@Produce		json
+//	@Success		202	{object}	MagicLinkResponse
+//	@Failure		400	{object}	internal.ErrorResponse	"Invalid JSON body"
+//	@Router			/api/v1/auth/magic-link [post]
+func (s *Server) MagicLink(w http.ResponseWriter, r *http.Request) {
+	var req MagicLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		internal.APIError(w, "Http::MagicLink", "Invalid JSON body", http.StatusBadRequest, err)
+		return
+	}
+
+	// Lookup & mailing happen in background, so response time doesn't
+	// reveal whether the email is registered either
+	go s.sendMagicLink(strings.TrimSpace(req.Email))
+
+	writeJSON(w, "Http::MagicLink", http.StatusAccepted, MagicLinkResponse{
+		Message: "If the email is registered, a signin link has been sent to it",
+	})
+}
+
+// Mails magic signin link to the user having given email, if any.
+func (s *Server) sendMagicLink(email string) {
+	user, err := s.UserService.FindUserByEmail(email)
+	if err != nil {
+		if !errors.Is(err, internal.ErrNotFound) {
+			internal.Error("Http::MagicLink", "Couldn't find user", err)
+		}
+		return
+	}
+
+	token, err := s.issueUserToken(user, internal.UserTokenMagicLink, magicLinkTTL)
+	if err == nil {
+		err = s.Mailer.SendMail(&internal.Mail{
+			To:      user.Email,
+			Subject: "Your signin link",
+			Body: "Hi " + user.Name + ",\n\n" +
+				"Please open the link below to signin. It can be used once and is valid for 15 minutes.\n" +
+				"If you didn't ask for it, you can ignore this mail.\n\n" +
+				s.AppURL + "/magic-link?token=" + url.QueryEscape(token) + "\n",
+		})
+	}
+	if err != nil {
+		internal.Error("Http::MagicLink", "Failed to send magic link mail", err)
+	}
+}
+
+// Represents Magic Link Consume Request
+type MagicLinkConsumeRequest struct {
+	Token string `json:"token" example:"kKxVbHn0Ry0Ku2pV5Wm1Yf3b2c5ZV7H9HnRkq1xTzJQ"` // Token from the signin link
+}
+
+// MagicLinkConsume godoc
+//
+//	@Summary		Magic Link Consume API
+//	@Description	Signs in with the single use token from the magic link. Opening the link proves the email, so unverified emails get verified.
+//	@Tags			auth
+//	@Accept			json
+//	@Param			input	body	MagicLinkConsumeRequest	true	"Signin Token"
+//	@Produce		json
+//	@Success		200	{object}	SigninResponse
+//	@Success		202	{object}	MFAChallengeResponse	"Second factor required, continue with MFA APIs"
+//	@Failure		400	{object}	internal.ErrorResponse	"Invalid or expired token"
+//	@Failure		429	{object}	internal.ErrorResponse	"Account locked"
+//	@Header			429	{integer}	Retry-After				"Seconds until the account is unlocked"
+//	@Failure		500	{object}	internal.ErrorResponse	"Server error"
+//	@Router			/api/v1/auth/magic-link/consume [post]
+func (s *Server) MagicLinkConsume(w http.ResponseWriter, r *http.Request) {
+	var req MagicLinkConsumeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" {
+		internal.APIError(w, "Http::MagicLinkConsume", "Invalid JSON body", http.StatusBadRequest, err)
+		return
+	}
+
+	t, err := s.UserTokenService.ConsumeUserToken(internal.UserTokenMagicLink, hashToken(req.Token))
+	if err != nil {
+		if errors.Is(err, internal.ErrNotFound) {
+			internal.APIError(w, "Http::MagicLinkConsume", "Invalid or expired token", http.StatusBadRequest, err)
+			return
+		}
+		internal.APIError(w, "Http::MagicLinkConsume", "Couldn't verify token", http.StatusInternalServerError, err)
+		return
+	}
+
+	user, err := s.UserService.FindUserByID(int(t.UserID))
+	if err != nil {
+		if errors.Is(err, internal.ErrNotFound) {
+			internal.APIError(w, "Http::MagicLinkConsume", "Invalid or expired token", http.StatusBadRequest, err)
+			return
+		}
+		internal.APIError(w, "Http::MagicLinkConsume", "Couldn't find user", http.StatusInternalServerError, err)
+		return
+	}
+
+	// Locked accounts are rejected, even with a valid link
+	if rejectLocked(w, "Http::MagicLinkConsume", user) {
+		return
+	}
+
+	if user.EmailVerifiedAt == nil {
+		if err := s.UserService.VerifyUserEmail(int(user.ID)); err != nil {
+			internal.APIError(w, "Http::MagicLinkConsume", "Couldn't verify email", http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	// Second factor, if enrolled or required by user roles
+	if s.challengeMFA(w, "Http::MagicLinkConsume", user) {
+		return
+	}
+
+	s.completeSignin(w, r, "Http::MagicLinkConsume", user)
+}
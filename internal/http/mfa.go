@@ -0,0 +1,404 @@
+package http
+
+import (
+	"go-api/internal"
+	"go-api/internal/totp"
+
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+const (
+	mfaTokenType      = "mfa"           // `typ` claim of MFA challenge tokens
+	mfaChallengeTTL   = 5 * time.Minute // Time given for completing the second factor
+	recoveryCodeCount = 10              // Recovery codes issued on MFA activation
+)
+
+// Helper function for registering MFA routes on the auth module mux.
+func (s *Server) registerMFARoutes(sm *http.ServeMux) {
+	sm.Handle("POST /mfa/enroll", s.IsAuthenticatedOrMFAChallenged(requireAccessToken(forbidImpersonation(http.HandlerFunc(s.MFAEnroll)))))
+	sm.Handle("POST /mfa/activate", s.IsAuthenticatedOrMFAChallenged(requireAccessToken(forbidImpersonation(http.HandlerFunc(s.MFAActivate)))))
+	sm.Handle("POST /mfa/verify", s.IsMFAChallenged(http.HandlerFunc(s.MFAVerify)))
+	sm.Handle("POST /mfa/disable", s.IsAuthenticated(requireAccessToken(forbidImpersonation(http.HandlerFunc(s.MFADisable)))))
+}
+
+// Represents response of Signin when a second factor is needed
+type MFAChallengeResponse struct {
+	MFAToken           string `json:"mfaToken" example:"eyJhbGciOiJFZERTQSIsImtpZCI6Ik56YkxzWGg4dURDY2QiLCJ0eXAiOiJKV1QifQ.e30.c2ln"` // Short-lived MFA Challenge Token, sent as Bearer token to MFA APIs
+	ExpiresAt          string `json:"expiresAt" example:"2024-05-03T15:39:26.460Z"`                                                   // MFA Challenge Token Expiry Time
+	EnrollmentRequired bool   `json:"enrollmentRequired" example:"false"`                                                             // User roles require MFA which isn't activated yet: enroll & activate before verifying
+}
+
+// Writes MFA challenge response if the user has MFA enabled or required by
+// the roles. Returns true if a response was written.
+func (s *Server) challengeMFA(w http.ResponseWriter, module string, user *internal.User) bool {
+	mfa, err := s.MFAService.FindMFA(int(user.ID))
+	if err != nil && !errors.Is(err, internal.ErrNotFound) {
+		internal.APIError(w, module, "Couldn't check MFA", http.StatusInternalServerError, err)
+		return true
+	}
+	enabled := mfa != nil && mfa.EnabledAt != nil
+	if !enabled && !user.MFARequired {
+		return false
+	}
+
+	jti, err := generateOpaqueToken()
+	if err != nil {
+		internal.APIError(w, module, "Failed to generate MFA token", http.StatusInternalServerError, err)
+		return true
+	}
+	expiresAt := time.Now().Add(mfaChallengeTTL)
+	token, err := s.Keys.Sign(jwt.MapClaims{
+		"jti": jti,
+		"typ": mfaTokenType,
+		"sub": user.ID,
+		"iat": time.Now().Unix(),
+		"exp": expiresAt.Unix(),
+	})
+	if err != nil {
+		internal.APIError(w, module, "Failed to generate MFA token", http.StatusInternalServerError, err)
+		return true
+	}
+
+	writeJSON(w, module, http.StatusAccepted, MFAChallengeResponse{
+		MFAToken:           token,
+		ExpiresAt:          expiresAt.UTC().Format("2006-01-02T15:04:05.000Z"),
+		EnrollmentRequired: !enabled,
+	})
+	return true
+}
+
+// Holds ID & expiry of the MFA challenge token of the request
+type mfaChallengeContextKey struct{}
+
+type mfaChallenge struct {
+	ID        string
+	ExpiresAt time.Time
+}
+
+// IsMFAChallenged Middleware for authorizing requests with the MFA Challenge
+// Token from Signin as Bearer token. Loads the challenged user into context.
+func (s *Server) IsMFAChallenged(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, err := s.parseToken(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), mfaTokenType)
+		if err != nil {
+			internal.APIError(w, "Http::IsMFAChallenged", "Invalid MFA token", http.StatusUnauthorized, err)
+			return
+		}
+
+		jti := claims["jti"].(string)
+		revoked, err := s.TokenRevocationStore.IsTokenRevoked(jti)
+		if err != nil {
+			internal.APIError(w, "Http::IsMFAChallenged", "Couldn't check MFA token", http.StatusInternalServerError, err)
+			return
+		}
+		if revoked {
+			internal.APIError(w, "Http::IsMFAChallenged", "MFA token expired", http.StatusUnauthorized, nil)
+			return
+		}
+
+		sub, _ := claims["sub"].(float64)
+		user, err := s.UserService.FindUserByID(int(sub))
+		if err != nil {
+			internal.APIError(w, "Http::IsMFAChallenged", "Token User not found", http.StatusUnauthorized, err)
+			return
+		}
+
+		exp, _ := claims["exp"].(float64)
+		ctx := internal.NewContextWithUser(r.Context(), user)
+		ctx = context.WithValue(ctx, mfaChallengeContextKey{}, mfaChallenge{ID: jti, ExpiresAt: time.Unix(int64(exp), 0)})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// IsAuthenticatedOrMFAChallenged Middleware accepts either an Access Token or
+// an MFA Challenge Token, so users whose roles require MFA can enroll during
+// signin.
+func (s *Server) IsAuthenticatedOrMFAChallenged(next http.Handler) http.Handler {
+	authenticated := s.IsAuthenticated(next)
+	challenged := s.IsMFAChallenged(next)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if _, err := s.parseToken(token, mfaTokenType); err == nil {
+			challenged.ServeHTTP(w, r)
+			return
+		}
+		authenticated.ServeHTTP(w, r)
+	})
+}
+
+// Represents MFA Enrollment Response
+type MFAEnrollResponse struct {
+	Secret string `json:"secret" example:"JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`                                                                // Base32 TOTP Secret, for manual entry
+	URI    string `json:"uri" example:"otpauth://totp/Kushan%20GO:ganesh@dwij.in?issuer=Kushan+GO&secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"` // otpauth:// URI, to be shown as QR code
+}
+
+// MFAEnroll godoc
+//
+//	@Summary		Enroll MFA
+//	@Description	Starts TOTP enrollment with a new secret for authenticator apps. MFA is enabled once activated with a valid code. Accepts Access Token or MFA Challenge Token.
+//	@Tags			auth
+//	@Produce		json
+//	@Success		200	{object}	MFAEnrollResponse
+//	@Failure		401	{object}	internal.ErrorResponse	"Invalid Bearer Token"
+//	@Failure		403	{object}	internal.ErrorResponse	"Authenticated with API Key"
+//	@Failure		409	{object}	internal.ErrorResponse	"MFA already enabled"
+//	@Failure		500	{object}	internal.ErrorResponse	"Server error"
+//	@Router			/api/v1/auth/mfa/enroll [post]
+//	@Security		Bearer
+func (s *Server) MFAEnroll(w http.ResponseWriter, r *http.Request) {
+	user := internal.UserFromContext(r.Context())
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		internal.APIError(w, "Http::MFAEnroll", "Failed to generate secret", http.StatusInternalServerError, err)
+		return
+	}
+
+	if err := s.MFAService.CreateMFA(&internal.MFA{UserID: user.ID, Secret: secret}); err != nil {
+		if errors.Is(err, internal.ErrConflict) {
+			internal.APIError(w, "Http::MFAEnroll", "MFA already enabled", http.StatusConflict, err)
+			return
+		}
+		internal.APIError(w, "Http::MFAEnroll", "Couldn't enroll MFA", http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, "Http::MFAEnroll", http.StatusOK, MFAEnrollResponse{
+		Secret: secret,
+		URI:    totp.URI(s.MFAIssuer, user.Email, secret),
+	})
+}
+
+// Represents MFA Code Request. Either code or recovery code is needed.
+type MFACodeRequest struct {
+	Code         string `json:"code" example:"123456"`              // TOTP Code from authenticator app
+	RecoveryCode string `json:"recoveryCode" example:"abcde-fghij"` // Single use Recovery Code, instead of TOTP Code
+}
+
+// Represents MFA Activation Response
+type MFAActivateResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes" example:"['abcde-fghij']"` // Single use Recovery Codes, shown only once
+}
+
+// MFAActivate godoc
+//
+//	@Summary		Activate MFA
+//	@Description	Enables MFA enrolled earlier, after checking a TOTP code from the authenticator app. Returns recovery codes, shown only once. Accepts Access Token or MFA Challenge Token.
+//	@Tags			auth
+//	@Accept			json
+//	@Param			input	body	MFACodeRequest	true	"TOTP Code"
+//	@Produce		json
+//	@Success		200	{object}	MFAActivateResponse
+//	@Failure		400	{object}	internal.ErrorResponse	"Invalid JSON body or code"
+//	@Failure		401	{object}	internal.ErrorResponse	"Invalid Bearer Token"
+//	@Failure		403	{object}	internal.ErrorResponse	"Authenticated with API Key"
+//	@Failure		404	{object}	internal.ErrorResponse	"No pending MFA enrollment"
+//	@Failure		500	{object}	internal.ErrorResponse	"Server error"
+//	@Router			/api/v1/auth/mfa/activate [post]
+//	@Security		Bearer
+func (s *Server) MFAActivate(w http.ResponseWriter, r *http.Request) {
+	user := internal.UserFromContext(r.Context())
+
+	var req MFACodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		internal.APIError(w, "Http::MFAActivate", "Invalid JSON body", http.StatusBadRequest, err)
+		return
+	}
+
+	mfa, err := s.MFAService.FindMFA(int(user.ID))
+	if err != nil || mfa.EnabledAt != nil {
+		internal.APIError(w, "Http::MFAActivate", "No pending MFA enrollment", http.StatusNotFound, err)
+		return
+	}
+	if _, ok := totp.Validate(mfa.Secret, req.Code, time.Now()); !ok {
+		internal.APIError(w, "Http::MFAActivate", "Invalid code", http.StatusBadRequest, nil)
+		return
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		if codes[i], err = generateRecoveryCode(); err != nil {
+			internal.APIError(w, "Http::MFAActivate", "Failed to generate recovery codes", http.StatusInternalServerError, err)
+			return
+		}
+		hashes[i] = hashToken(normalizeRecoveryCode(codes[i]))
+	}
+
+	if err := s.MFAService.EnableMFA(int(user.ID), hashes); err != nil {
+		internal.APIError(w, "Http::MFAActivate", "Couldn't activate MFA", errorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, "Http::MFAActivate", http.StatusOK, MFAActivateResponse{RecoveryCodes: codes})
+}
+
+// MFAVerify godoc
+//
+//	@Summary		Verify MFA
+//	@Description	Completes signin with a TOTP code or a recovery code, using the MFA Challenge Token from Signin as Bearer token. Wrong codes count as failed signins: once the account gets locked, the challenge is revoked.
+//	@Tags			auth
+//	@Accept			json
+//	@Param			input	body	MFACodeRequest	true	"TOTP Code or Recovery Code"
+//	@Produce		json
+//	@Success		200	{object}	SigninResponse
+//	@Failure		400	{object}	internal.ErrorResponse	"Invalid JSON body"
+//	@Failure		401	{object}	internal.ErrorResponse	"Invalid MFA token or code"
+//	@Failure		429	{object}	internal.ErrorResponse	"Account locked after too many failed signins"
+//	@Header			429	{integer}	Retry-After				"Seconds until the account is unlocked"
+//	@Failure		500	{object}	internal.ErrorResponse	"Server error"
+//	@Router			/api/v1/auth/mfa/verify [post]
+//	@Security		Bearer
+func (s *Server) MFAVerify(w http.ResponseWriter, r *http.Request) {
+	user := internal.UserFromContext(r.Context())
+
+	var req MFACodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		internal.APIError(w, "Http::MFAVerify", "Invalid JSON body", http.StatusBadRequest, err)
+		return
+	}
+
+	// Locked accounts are rejected, even with the right code
+	if rejectLocked(w, "Http::MFAVerify", user) {
+		return
+	}
+
+	challenge, _ := r.Context().Value(mfaChallengeContextKey{}).(mfaChallenge)
+	if err := s.verifySecondFactor(user, req); err != nil {
+		// Wrong codes count as failed signins. Once the account gets locked,
+		// the challenge is revoked, so codes can't be guessed further.
+		if s.recordFailedSignin(user) {
+			if err := s.TokenRevocationStore.RevokeToken(challenge.ID, challenge.ExpiresAt); err != nil {
+				internal.Error("Http::MFAVerify", "Couldn't revoke MFA token", err)
+			}
+		}
+		internal.APIError(w, "Http::MFAVerify", err.Error(), http.StatusUnauthorized, err)
+		return
+	}
+
+	// Challenge is single use
+	if err := s.TokenRevocationStore.RevokeToken(challenge.ID, challenge.ExpiresAt); err != nil {
+		internal.APIError(w, "Http::MFAVerify", "Couldn't revoke MFA token", http.StatusInternalServerError, err)
+		return
+	}
+
+	s.resetFailedSignins("Http::MFAVerify", user)
+	s.completeSignin(w, r, "Http::MFAVerify", user)
+}
+
+// Represents MFA Disable Request
+type MFADisableRequest struct {
+	Password string `json:"password" example:"Secret123"` // Current Password
+}
+
+// Represents MFA Disable Response
+type MFADisableResponse struct {
+	Message string `json:"message" example:"MFA Disabled"` // Result Message
+}
+
+// MFADisable godoc
+//
+//	@Summary		Disable MFA
+//	@Description	Disables MFA of the signed in User after checking the password. Not allowed if User roles require MFA.
+//	@Tags			auth
+//	@Accept			json
+//	@Param			input	body	MFADisableRequest	true	"Current Password"
+//	@Produce		json
+//	@Success		200	{object}	MFADisableResponse
+//	@Failure		400	{object}	internal.ErrorResponse	"Invalid JSON body"
+//	@Failure		401	{object}	internal.ErrorResponse	"Invalid Bearer Token or password"
+//	@Failure		403	{object}	internal.ErrorResponse	"MFA required by roles, or authenticated with API Key"
+//	@Failure		404	{object}	internal.ErrorResponse	"MFA not enrolled"
+//	@Failure		429	{object}	internal.ErrorResponse	"Account locked after too many failed signins"
+//	@Header			429	{integer}	Retry-After				"Seconds until the account is unlocked"
+//	@Failure		500	{object}	internal.ErrorResponse	"Server error"
+//	@Router			/api/v1/auth/mfa/disable [post]
+//	@Security		Bearer
+func (s *Server) MFADisable(w http.ResponseWriter, r *http.Request) {
+	var req MFADisableRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		internal.APIError(w, "Http::MFADisable", "Invalid JSON body", http.StatusBadRequest, err)
+		return
+	}
+
+	// Reload user with the password hash
+	user, err := s.UserService.FindUserByEmail(internal.UserFromContext(r.Context()).Email)
+	if err != nil {
+		internal.APIError(w, "Http::MFADisable", "Token User not found", http.StatusUnauthorized, err)
+		return
+	}
+
+	// Wrong passwords count as failed signins, so they can't be guessed
+	// here past the lockout
+	_, passwordErr := s.Passwords.Verify(user.Password, req.Password)
+	if rejectLocked(w, "Http::MFADisable", user) {
+		return
+	}
+	if passwordErr != nil {
+		s.recordFailedSignin(user)
+		internal.APIError(w, "Http::MFADisable", "Invalid password", http.StatusUnauthorized, passwordErr)
+		return
+	}
+	if user.MFARequired {
+		internal.APIError(w, "Http::MFADisable", "MFA is required by user roles", http.StatusForbidden, nil)
+		return
+	}
+
+	if err := s.MFAService.DeleteMFA(int(user.ID)); err != nil {
+		internal.APIError(w, "Http::MFADisable", "MFA not enrolled", errorStatus(err), err)
+		return
+	}
+	s.resetFailedSignins("Http::MFADisable", user)
+
+	writeJSON(w, "Http::MFADisable", http.StatusOK, MFADisableResponse{Message: "MFA Disabled"})
+}
+
+// Checks TOTP code or recovery code of the user with enabled MFA. Accepted
+// codes can't be used again.
+func (s *Server) verifySecondFactor(user *internal.User, req MFACodeRequest) error {
+	mfa, err := s.MFAService.FindMFA(int(user.ID))
+	if err != nil || mfa.EnabledAt == nil {
+		return errors.New("MFA not activated")
+	}
+
+	if req.RecoveryCode != "" {
+		if err := s.MFAService.UseRecoveryCode(int(user.ID), hashToken(normalizeRecoveryCode(req.RecoveryCode))); err != nil {
+			return errors.New("invalid recovery code")
+		}
+		return nil
+	}
+
+	step, ok := totp.Validate(mfa.Secret, req.Code, time.Now())
+	if !ok {
+		return errors.New("invalid code")
+	}
+	if err := s.MFAService.UseMFAStep(int(user.ID), step); err != nil {
+		return errors.New("code already used")
+	}
+	return nil
+}
+
+// Generates a random recovery code like "abcde-fghij", with 50 bits of entropy.
+func generateRecoveryCode() (string, error) {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	code := strings.ToLower(base32.StdEncoding.EncodeToString(b))[:10]
+	return code[:5] + "-" + code[5:], nil
+}
+
+// Normalizes recovery code for hashing, so case & separators don't matter.
+func normalizeRecoveryCode(code string) string {
+	return strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(code))
+}
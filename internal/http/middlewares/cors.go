@@ -2,23 +2,45 @@ package middlewares
 
 import "net/http"
 
+// CorsConfig configures AllowCors. Set it before serving requests.
+type CorsConfig struct {
+	// Origins allowed to make cross-origin requests
+	AllowedOrigins []string
+
+	// Whether browsers may send cookies with cross-origin requests, needed
+	// by the cookie auth mode. Origins must not be wildcard then.
+	AllowCredentials bool
+}
+
+// Cors is the configuration used by AllowCors.
+var Cors = CorsConfig{
+	AllowedOrigins:   []string{"http://localhost:8083", "http://localhost:8084", "https://kushant-go.dwij.in"},
+	AllowCredentials: true,
+}
+
 func AllowCors(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
-		// Allow all origins (you may want to restrict this to specific origins)
-		switch origin := r.Header.Get("Origin"); origin {
-		case "http://localhost:8083", "http://localhost:8084", "https://kushant-go.dwij.in":
-			w.Header().Set("Access-Control-Allow-Origin", origin)
+		// Allow configured origins only
+		origin := r.Header.Get("Origin")
+		for _, allowed := range Cors.AllowedOrigins {
+			if origin != "" && origin == allowed {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+				break
+			}
 		}
+		w.Header().Add("Vary", "Origin")
 
 		// Allow specific methods
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
 
 		// Allow Credentials
-		w.Header().Set("Access-Control-Allow-Credentials", "true")
+		if Cors.AllowCredentials {
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
 
 		// Allow specific headers
-		w.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization, X-API-Key, X-CSRF-Token")
 
 		// Handle preflight requests (OPTIONS)
 		if r.Method == http.MethodOptions {
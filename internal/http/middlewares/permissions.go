@@ -0,0 +1,31 @@
+package middlewares
+
+import (
+	"go-api/internal"
+
+	"net/http"
+)
+
+// RequirePermission creates middleware which lets the request through only if
+// the current user has all of the given permissions. User is read from the
+// request context, so it must run after authentication middleware.
+func RequirePermission(permissions ...string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user := internal.UserFromContext(r.Context())
+			if user == nil {
+				internal.APIError(w, "Middleware::RequirePermission", "Authentication required", http.StatusUnauthorized, nil)
+				return
+			}
+
+			for _, permission := range permissions {
+				if !user.HasPermission(permission) {
+					internal.APIError(w, "Middleware::RequirePermission", "Missing permission "+permission, http.StatusForbidden, nil)
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
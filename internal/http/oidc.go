@@ -0,0 +1,238 @@
+package http
+
+import (
+	"go-api/internal"
+	"go-api/internal/oidc"
+
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	oidcStateCookie = "oidc_state"         // Cookie carrying state of the signin in progress
+	oidcStateTTL    = 10 * time.Minute     // Time given for signing in at the provider
+	oidcCookiePath  = "/api/v1/auth/oidc/" // Cookie is only sent back to the OIDC routes
+)
+
+// Represents an OpenID Connect provider users can signin with.
+type OIDCProvider struct {
+	*oidc.Provider
+
+	// Creates users signing in for the first time, instead of rejecting
+	// them. Existing users are linked by verified email either way.
+	AutoProvision bool
+}
+
+// State of the signin in progress, kept in a signed cookie between login & callback
+type oidcState struct {
+	Provider  string `json:"p"`
+	State     string `json:"s"`
+	Nonce     string `json:"n"`
+	Verifier  string `json:"v"`
+	ExpiresAt int64  `json:"e"`
+}
+
+// Helper function for registering OIDC routes on the auth module mux.
+func (s *Server) registerOIDCRoutes(sm *http.ServeMux) {
+	sm.HandleFunc("GET /oidc/{provider}/login", s.OIDCLogin)
+	sm.HandleFunc("GET /oidc/{provider}/callback", s.OIDCCallback)
+}
+
+// OIDCLogin godoc
+//
+//	@Summary		Sign In with Identity Provider
+//	@Description	Redirects to the OpenID Connect provider for signin, using Authorization Code flow with PKCE. The provider redirects back to the callback API.
+//	@Tags			auth
+//	@Param			provider	path	string	true	"Provider name"	default(corp)
+//	@Success		302
+//	@Header			302	{string}	Location				"Authorization URL of the provider"
+//	@Failure		404	{object}	internal.ErrorResponse	"Provider not found"
+//	@Failure		502	{object}	internal.ErrorResponse	"Provider unreachable"
+//	@Router			/api/v1/auth/oidc/{provider}/login [get]
+func (s *Server) OIDCLogin(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("provider")
+	provider := s.OIDCProviders[name]
+	if provider == nil {
+		internal.APIError(w, "Http::OIDCLogin", "Provider not found", http.StatusNotFound, nil)
+		return
+	}
+
+	state := oidcState{Provider: name, ExpiresAt: time.Now().Add(oidcStateTTL).Unix()}
+	var err error
+	if state.State, err = generateOpaqueToken(); err == nil {
+		if state.Nonce, err = generateOpaqueToken(); err == nil {
+			state.Verifier, err = oidc.GenerateVerifier()
+		}
+	}
+	if err != nil {
+		internal.APIError(w, "Http::OIDCLogin", "Failed to generate state", http.StatusInternalServerError, err)
+		return
+	}
+
+	authURL, err := provider.AuthCodeURL(r.Context(), state.State, state.Nonce, state.Verifier)
+	if err != nil {
+		internal.APIError(w, "Http::OIDCLogin", "Provider unreachable", http.StatusBadGateway, err)
+		return
+	}
+
+	cookie, err := signValue(s.SigningSecret, signPurposeOIDCState, state)
+	if err != nil {
+		internal.APIError(w, "Http::OIDCLogin", "Failed to sign state", http.StatusInternalServerError, err)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    cookie,
+		Path:     oidcCookiePath,
+		MaxAge:   int(oidcStateTTL / time.Second),
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(provider.RedirectURL, "https://"),
+		SameSite: http.SameSiteLaxMode, // Sent on the top level redirect back from the provider
+	})
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// OIDCCallback godoc
+//
+//	@Summary		Identity Provider Callback
+//	@Description	Completes signin with the OpenID Connect provider. Users are matched by the provider identity, else linked by verified email, else created if the provider allows it.
+//	@Tags			auth
+//	@Param			provider	path	string	true	"Provider name"	default(corp)
+//	@Param			code		query	string	true	"Authorization Code"
+//	@Param			state		query	string	true	"State from Login"
+//	@Produce		json
+//	@Success		200	{object}	SigninResponse
+//	@Success		202	{object}	MFAChallengeResponse	"Second factor required, continue with MFA APIs"
+//	@Failure		400	{object}	internal.ErrorResponse	"Invalid or expired state"
+//	@Failure		401	{object}	internal.ErrorResponse	"Signin failed at the provider"
+//	@Failure		403	{object}	internal.ErrorResponse	"No account for the identity"
+//	@Failure		404	{object}	internal.ErrorResponse	"Provider not found"
+//	@Failure		429	{object}	internal.ErrorResponse	"Account locked"
+//	@Header			429	{integer}	Retry-After				"Seconds until the account is unlocked"
+//	@Failure		500	{object}	internal.ErrorResponse	"Server error"
+//	@Router			/api/v1/auth/oidc/{provider}/callback [get]
+func (s *Server) OIDCCallback(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("provider")
+	provider := s.OIDCProviders[name]
+	if provider == nil {
+		internal.APIError(w, "Http::OIDCCallback", "Provider not found", http.StatusNotFound, nil)
+		return
+	}
+
+	// State is single use, clear it whatever the outcome
+	http.SetCookie(w, &http.Cookie{Name: oidcStateCookie, Path: oidcCookiePath, MaxAge: -1, HttpOnly: true})
+
+	var state oidcState
+	cookie, err := r.Cookie(oidcStateCookie)
+	if err == nil {
+		err = verifyValue(s.SigningSecret, signPurposeOIDCState, cookie.Value, &state)
+	}
+	query := r.URL.Query()
+	if err != nil || state.Provider != name || time.Now().Unix() > state.ExpiresAt ||
+		subtle.ConstantTimeCompare([]byte(state.State), []byte(query.Get("state"))) != 1 {
+		internal.APIError(w, "Http::OIDCCallback", "Invalid or expired state", http.StatusBadRequest, err)
+		return
+	}
+
+	if e := query.Get("error"); e != "" {
+		internal.APIError(w, "Http::OIDCCallback", "Signin failed at the provider: "+e, http.StatusUnauthorized, errors.New(query.Get("error_description")))
+		return
+	}
+
+	claims, err := provider.Exchange(r.Context(), query.Get("code"), state.Verifier, state.Nonce)
+	if err != nil {
+		internal.APIError(w, "Http::OIDCCallback", "Signin failed at the provider", http.StatusUnauthorized, err)
+		return
+	}
+
+	user, err := s.oidcUser(name, provider, claims)
+	if err != nil {
+		if errors.Is(err, internal.ErrNotFound) {
+			internal.APIError(w, "Http::OIDCCallback", "No account for the identity", http.StatusForbidden, err)
+			return
+		}
+		internal.APIError(w, "Http::OIDCCallback", "Couldn't find user", http.StatusInternalServerError, err)
+		return
+	}
+
+	// Locked accounts are rejected, even when the provider signed them in
+	if rejectLocked(w, "Http::OIDCCallback", user) {
+		return
+	}
+
+	// Second factor, if enrolled or required by user roles
+	if s.challengeMFA(w, "Http::OIDCCallback", user) {
+		return
+	}
+
+	s.completeSignin(w, r, "Http::OIDCCallback", user)
+}
+
+// Returns the user of the provider identity. Unknown identities are linked
+// to the user having the same verified email, or to a new user if the
+// provider allows provisioning. Returns ErrNotFound otherwise.
+func (s *Server) oidcUser(name string, provider *OIDCProvider, claims *oidc.Claims) (*internal.User, error) {
+	identity, err := s.IdentityService.FindIdentity(name, claims.Subject)
+	if err == nil {
+		return s.UserService.FindUserByID(int(identity.UserID))
+	}
+	if !errors.Is(err, internal.ErrNotFound) {
+		return nil, err
+	}
+
+	// Emails not verified by the provider could belong to anyone
+	if claims.Email == "" || !claims.EmailVerified {
+		return nil, internal.ErrNotFound
+	}
+
+	user, err := s.UserService.FindUserByEmail(claims.Email)
+	if errors.Is(err, internal.ErrNotFound) && provider.AutoProvision {
+		user, err = s.provisionOIDCUser(claims)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if user.EmailVerifiedAt == nil {
+		if err := s.UserService.VerifyUserEmail(int(user.ID)); err != nil {
+			return nil, err
+		}
+	}
+
+	identity = &internal.Identity{UserID: user.ID, Provider: name, Subject: claims.Subject, Email: claims.Email}
+	if err := s.IdentityService.CreateIdentity(identity); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// Creates a verified user for the provider identity. The user gets a random
+// password, so can only signin through the provider until it's reset.
+func (s *Server) provisionOIDCUser(claims *oidc.Claims) (*internal.User, error) {
+	password, err := generateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	passwordHash, err := s.hashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(claims.Name)
+	if name == "" {
+		name, _, _ = strings.Cut(claims.Email, "@")
+	}
+	now := time.Now().UTC()
+	user := internal.User{
+		Name:            name,
+		Email:           claims.Email,
+		Password:        passwordHash,
+		EmailVerifiedAt: &now,
+	}
+	if err := s.UserService.CreateUser(&user); err != nil {
+		return nil, err
+	}
+	return s.UserService.FindUserByID(int(user.ID))
+}
@@ -0,0 +1,283 @@
+package http
+
+import (
+	"go-api/internal"
+	"go-api/internal/oidc"
+	"go-api/tests"
+
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// In-memory services backing the OIDC signin flow. Methods the flow
+// doesn't use panic through the nil embedded interfaces.
+type fakeUserService struct {
+	internal.UserService
+	mu    sync.Mutex
+	users []*internal.User
+}
+
+func (s *fakeUserService) FindUserByID(id int) (*internal.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, u := range s.users {
+		if int(u.ID) == id {
+			copy := *u
+			return &copy, nil
+		}
+	}
+	return nil, internal.ErrNotFound
+}
+
+func (s *fakeUserService) FindUserByEmail(email string) (*internal.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, u := range s.users {
+		if u.Email == email {
+			copy := *u
+			return &copy, nil
+		}
+	}
+	return nil, internal.ErrNotFound
+}
+
+func (s *fakeUserService) CreateUser(user *internal.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user.ID = uint(len(s.users) + 1)
+	copy := *user
+	s.users = append(s.users, &copy)
+	return nil
+}
+
+func (s *fakeUserService) VerifyUserEmail(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, u := range s.users {
+		if int(u.ID) == id && u.EmailVerifiedAt == nil {
+			now := time.Now()
+			u.EmailVerifiedAt = &now
+		}
+	}
+	return nil
+}
+
+type fakeIdentityService struct {
+	identities []*internal.Identity
+}
+
+func (s *fakeIdentityService) FindIdentity(provider string, subject string) (*internal.Identity, error) {
+	for _, i := range s.identities {
+		if i.Provider == provider && i.Subject == subject {
+			return i, nil
+		}
+	}
+	return nil, internal.ErrNotFound
+}
+
+func (s *fakeIdentityService) CreateIdentity(i *internal.Identity) error {
+	i.ID = uint(len(s.identities) + 1)
+	s.identities = append(s.identities, i)
+	return nil
+}
+
+type fakeMFAService struct{ internal.MFAService }
+
+func (s *fakeMFAService) FindMFA(userID int) (*internal.MFA, error) {
+	return nil, internal.ErrNotFound
+}
+
+type fakeSessionService struct{ internal.SessionService }
+
+func (s *fakeSessionService) CreateSession(session *internal.Session) error { return nil }
+
+type fakeRefreshTokenService struct{ internal.RefreshTokenService }
+
+func (s *fakeRefreshTokenService) CreateRefreshToken(t *internal.RefreshToken) error { return nil }
+
+// Returns a Server signing in through the stub IdP as provider "stub".
+func newOIDCTestServer(t *testing.T, idp *tests.StubIdP, autoProvision bool) *Server {
+	t.Helper()
+	s := NewServer(0)
+	s.UserService = &fakeUserService{}
+	s.IdentityService = &fakeIdentityService{}
+	s.MFAService = &fakeMFAService{}
+	s.SessionService = &fakeSessionService{}
+	s.RefreshTokenService = &fakeRefreshTokenService{}
+	s.OIDCProviders = map[string]*OIDCProvider{
+		"stub": {
+			Provider: oidc.NewProvider(oidc.Config{
+				Issuer:       idp.URL,
+				ClientID:     idp.ClientID,
+				ClientSecret: idp.ClientSecret,
+				RedirectURL:  "http://api.test/api/v1/auth/oidc/stub/callback",
+				Scopes:       []string{"openid", "email", "profile"},
+			}),
+			AutoProvision: autoProvision,
+		},
+	}
+	return s
+}
+
+// Runs login at the server & authorization at the stub IdP, and returns the
+// callback request the IdP redirects the browser to.
+func oidcCallbackRequest(t *testing.T, s *Server) *http.Request {
+	t.Helper()
+
+	login := httptest.NewRequest(http.MethodGet, "/oidc/stub/login", nil)
+	login.SetPathValue("provider", "stub")
+	rec := httptest.NewRecorder()
+	s.OIDCLogin(rec, login)
+	if rec.Code != http.StatusFound {
+		t.Fatalf("login status = %d, want %d: %s", rec.Code, http.StatusFound, rec.Body)
+	}
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(rec.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("authorize status = %d, want %d", resp.StatusCode, http.StatusFound)
+	}
+
+	callback := httptest.NewRequest(http.MethodGet, resp.Header.Get("Location"), nil)
+	callback.SetPathValue("provider", "stub")
+	for _, cookie := range rec.Result().Cookies() {
+		callback.AddCookie(cookie)
+	}
+	return callback
+}
+
+func TestOIDCSignin(t *testing.T) {
+	verified := time.Now()
+	locked := time.Now().Add(time.Hour)
+
+	cases := []struct {
+		name          string
+		autoProvision bool
+		users         []*internal.User // Existing users
+		setup         func(idp *tests.StubIdP)
+		tamper        func(s *Server, r *http.Request) // Changes the callback request
+		wantStatus    int
+		wantIdentity  bool
+	}{
+		{
+			name:         "links existing user by verified email",
+			users:        []*internal.User{{ID: 1, Name: "Stub", Email: "stub.user@example.com", EmailVerifiedAt: &verified}},
+			wantStatus:   http.StatusOK,
+			wantIdentity: true,
+		},
+		{
+			name:         "rejects locked user",
+			users:        []*internal.User{{ID: 1, Name: "Stub", Email: "stub.user@example.com", EmailVerifiedAt: &verified, LockedUntil: &locked}},
+			wantStatus:   http.StatusTooManyRequests,
+			wantIdentity: true,
+		},
+		{
+			name:          "provisions new user",
+			autoProvision: true,
+			wantStatus:    http.StatusOK,
+			wantIdentity:  true,
+		},
+		{
+			name:          "rejects unknown user without auto provision",
+			autoProvision: false,
+			wantStatus:    http.StatusForbidden,
+		},
+		{
+			name:          "rejects unverified email",
+			autoProvision: true,
+			users:         []*internal.User{{ID: 1, Name: "Stub", Email: "stub.user@example.com", EmailVerifiedAt: &verified}},
+			setup:         func(idp *tests.StubIdP) { idp.EmailVerified = false },
+			wantStatus:    http.StatusForbidden,
+		},
+		{
+			name:  "rejects state mismatch",
+			users: []*internal.User{{ID: 1, Name: "Stub", Email: "stub.user@example.com", EmailVerifiedAt: &verified}},
+			tamper: func(s *Server, r *http.Request) {
+				q := r.URL.Query()
+				q.Set("state", "forged")
+				r.URL.RawQuery = q.Encode()
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "rejects missing state cookie",
+			users: []*internal.User{{ID: 1, Name: "Stub", Email: "stub.user@example.com", EmailVerifiedAt: &verified}},
+			tamper: func(s *Server, r *http.Request) {
+				r.Header.Del("Cookie")
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "rejects nonce mismatch",
+			users:      []*internal.User{{ID: 1, Name: "Stub", Email: "stub.user@example.com", EmailVerifiedAt: &verified}},
+			setup:      func(idp *tests.StubIdP) { idp.Nonce = "replayed" },
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:  "rejects PKCE verifier mismatch",
+			users: []*internal.User{{ID: 1, Name: "Stub", Email: "stub.user@example.com", EmailVerifiedAt: &verified}},
+			tamper: func(s *Server, r *http.Request) {
+				cookie, _ := r.Cookie(oidcStateCookie)
+				var state oidcState
+				if err := verifyValue(s.SigningSecret, signPurposeOIDCState, cookie.Value, &state); err != nil {
+					panic(err)
+				}
+				state.Verifier += "x"
+				value, _ := signValue(s.SigningSecret, signPurposeOIDCState, state)
+				r.Header.Del("Cookie")
+				r.AddCookie(&http.Cookie{Name: oidcStateCookie, Value: value})
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			idp := tests.NewStubIdP("client", "secret")
+			defer idp.Close()
+			if tt.setup != nil {
+				tt.setup(idp)
+			}
+
+			s := newOIDCTestServer(t, idp, tt.autoProvision)
+			users := s.UserService.(*fakeUserService)
+			users.users = tt.users
+			identities := s.IdentityService.(*fakeIdentityService)
+
+			callback := oidcCallbackRequest(t, s)
+			if tt.tamper != nil {
+				tt.tamper(s, callback)
+			}
+			rec := httptest.NewRecorder()
+			s.OIDCCallback(rec, callback)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("callback status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if got := len(identities.identities) == 1; got != tt.wantIdentity {
+				t.Fatalf("identity linked = %v, want %v", got, tt.wantIdentity)
+			}
+			if tt.wantIdentity {
+				user, err := users.FindUserByEmail(idp.Email)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if identities.identities[0].UserID != user.ID || identities.identities[0].Subject != idp.Subject {
+					t.Fatalf("identity = %+v, want user %d subject %s", identities.identities[0], user.ID, idp.Subject)
+				}
+				if user.EmailVerifiedAt == nil {
+					t.Fatal("user email not verified")
+				}
+			}
+		})
+	}
+}
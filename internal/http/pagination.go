@@ -0,0 +1,98 @@
+package http
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Represents a single RFC 8288 link relation
+type pageLink struct {
+	Rel string
+	URL string
+}
+
+// Builds URL of the current request with the given query params replaced.
+// Params with empty value are removed from the query.
+func pageURL(r *http.Request, params map[string]string) string {
+	// RequestURI keeps the original path, before any http.StripPrefix
+	u, err := url.ParseRequestURI(r.RequestURI)
+	if err != nil {
+		u = &url.URL{Path: r.URL.Path}
+	}
+
+	q := r.URL.Query()
+	for k, v := range params {
+		if v == "" {
+			q.Del(k)
+		} else {
+			q.Set(k, v)
+		}
+	}
+	u.RawQuery = q.Encode()
+
+	return u.String()
+}
+
+// Builds first, prev, next & last links for offset pagination.
+func offsetPageLinks(r *http.Request, offset, limit, total int) []pageLink {
+	link := func(rel string, offset int) pageLink {
+		return pageLink{rel, pageURL(r, map[string]string{
+			"offset": strconv.Itoa(offset),
+			"limit":  strconv.Itoa(limit),
+			"cursor": "",
+		})}
+	}
+
+	links := []pageLink{link("first", 0)}
+	if offset > 0 {
+		links = append(links, link("prev", max(0, offset-limit)))
+	}
+	if offset+limit < total {
+		links = append(links, link("next", offset+limit))
+	}
+	if total > 0 {
+		links = append(links, link("last", (total-1)/limit*limit))
+	}
+	return links
+}
+
+// Builds first & next links for keyset (cursor) pagination. Next link is
+// omitted if there is no next cursor.
+func cursorPageLinks(r *http.Request, limit int, nextCursor string) []pageLink {
+	link := func(rel string, cursor string) pageLink {
+		return pageLink{rel, pageURL(r, map[string]string{
+			"offset": "",
+			"limit":  strconv.Itoa(limit),
+			"cursor": cursor,
+		})}
+	}
+
+	links := []pageLink{link("first", "")}
+	if nextCursor != "" {
+		links = append(links, link("next", nextCursor))
+	}
+	return links
+}
+
+// Finds the URL of given relation from links.
+func linkURL(links []pageLink, rel string) string {
+	for _, l := range links {
+		if l.Rel == rel {
+			return l.URL
+		}
+	}
+	return ""
+}
+
+// Writes the RFC 8288 Link header for the given links.
+func setLinkHeader(w http.ResponseWriter, links []pageLink) {
+	values := make([]string, 0, len(links))
+	for _, l := range links {
+		values = append(values, "<"+l.URL+`>; rel="`+l.Rel+`"`)
+	}
+	if len(values) > 0 {
+		w.Header().Set("Link", strings.Join(values, ", "))
+	}
+}
@@ -0,0 +1,238 @@
+package http
+
+import (
+	"go-api/internal"
+
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Validity of password reset links
+const passwordResetTTL = time.Hour
+
+// Represents Forgot Password Request
+type ForgotPasswordRequest struct {
+	Email string `json:"email" example:"ganesh@dwij.in"` // Email Address of the account
+}
+
+// Represents Password API Response
+type PasswordResponse struct {
+	Message string `json:"message" example:"Password Updated"` // Result Message
+}
+
+// ForgotPassword godoc
+//
+//	@Summary		Forgot Password API
+//	@Description	Mails a single use password reset link if the email is registered. Response is the same either way, so it doesn't reveal registered emails.
+//	@Tags			auth
+//	@Accept			json
+//	@Param			input	body	ForgotPasswordRequest	true	"Account Email"
+//	@Produce		json
+//	@Success		202	{object}	PasswordResponse
+//	@Failure		400	{object}	internal.ErrorResponse	"Invalid JSON body"
+//	@Router			/api/v1/auth/forgot-password [post]
+func (s *Server) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		internal.APIError(w, "Http::ForgotPassword", "Invalid JSON body", http.StatusBadRequest, err)
+		return
+	}
+
+	// Lookup & mailing happen in background, so response time doesn't
+	// reveal whether the email is registered either
+	go s.sendPasswordReset(strings.TrimSpace(req.Email))
+
+	writeJSON(w, "Http::ForgotPassword", http.StatusAccepted, PasswordResponse{
+		Message: "If the email is registered, a password reset link has been sent to it",
+	})
+}
+
+// Mails password reset link to the user having given email, if any.
+func (s *Server) sendPasswordReset(email string) {
+	user, err := s.UserService.FindUserByEmail(email)
+	if err != nil {
+		if !errors.Is(err, internal.ErrNotFound) {
+			internal.Error("Http::ForgotPassword", "Couldn't find user", err)
+		}
+		return
+	}
+
+	token, err := s.issueUserToken(user, internal.UserTokenPasswordReset, passwordResetTTL)
+	if err == nil {
+		err = s.Mailer.SendMail(&internal.Mail{
+			To:      user.Email,
+			Subject: "Reset your password",
+			Body: "Hi " + user.Name + ",\n\n" +
+				"Please open the link below to set a new password. It is valid for 1 hour.\n" +
+				"If you didn't ask for it, you can ignore this mail.\n\n" +
+				s.AppURL + "/reset-password?token=" + url.QueryEscape(token) + "\n",
+		})
+	}
+	if err != nil {
+		internal.Error("Http::ForgotPassword", "Failed to send password reset mail", err)
+	}
+}
+
+// Represents Reset Password Request
+type ResetPasswordRequest struct {
+	Token    string `json:"token" example:"kKxVbHn0Ry0Ku2pV5Wm1Yf3b2c5ZV7H9HnRkq1xTzJQ"` // Token from the reset link
+	Password string `json:"password" example:"Secret456"`                                // New Password, 8-72 chars with a letter & a digit
+}
+
+// ResetPassword godoc
+//
+//	@Summary		Reset Password API
+//	@Description	Sets a new password with the single use token from the reset link. Signs the user out everywhere.
+//	@Tags			auth
+//	@Accept			json
+//	@Param			input	body	ResetPasswordRequest	true	"Reset Token & New Password"
+//	@Produce		json
+//	@Success		200	{object}	PasswordResponse
+//	@Failure		400	{object}	internal.ErrorResponse	"Invalid or expired token, or invalid password"
+//	@Failure		500	{object}	internal.ErrorResponse	"Server error"
+//	@Router			/api/v1/auth/reset-password [post]
+func (s *Server) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" {
+		internal.APIError(w, "Http::ResetPassword", "Invalid JSON body", http.StatusBadRequest, err)
+		return
+	}
+	// Checked before consuming the token as far as possible without the
+	// user, so a weak password doesn't use up the reset link
+	if err := internal.ValidatePassword(req.Password, ""); err != nil {
+		internal.APIError(w, "Http::ResetPassword", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	t, err := s.UserTokenService.ConsumeUserToken(internal.UserTokenPasswordReset, hashToken(req.Token))
+	if err != nil {
+		if errors.Is(err, internal.ErrNotFound) {
+			internal.APIError(w, "Http::ResetPassword", "Invalid or expired token", http.StatusBadRequest, err)
+			return
+		}
+		internal.APIError(w, "Http::ResetPassword", "Couldn't verify token", http.StatusInternalServerError, err)
+		return
+	}
+	user, err := s.UserService.FindUserByID(int(t.UserID))
+	if err != nil {
+		if errors.Is(err, internal.ErrNotFound) {
+			internal.APIError(w, "Http::ResetPassword", "Invalid or expired token", http.StatusBadRequest, err)
+			return
+		}
+		internal.APIError(w, "Http::ResetPassword", "Couldn't load user", http.StatusInternalServerError, err)
+		return
+	}
+	if err := internal.ValidatePassword(req.Password, user.Email); err != nil {
+		internal.APIError(w, "Http::ResetPassword", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	passwordHash, err := s.hashPassword(req.Password)
+	if err != nil {
+		internal.APIError(w, "Http::ResetPassword", "Failed to hash password", http.StatusInternalServerError, err)
+		return
+	}
+	if err := s.setPassword(int(t.UserID), passwordHash); err != nil {
+		if errors.Is(err, internal.ErrNotFound) {
+			internal.APIError(w, "Http::ResetPassword", "Invalid or expired token", http.StatusBadRequest, err)
+			return
+		}
+		internal.APIError(w, "Http::ResetPassword", "Couldn't update password", http.StatusInternalServerError, err)
+		return
+	}
+
+	// Reset link proves the ownership of the email as well
+	if err := s.UserService.VerifyUserEmail(int(t.UserID)); err != nil {
+		internal.Error("Http::ResetPassword", "Couldn't verify email", err)
+	}
+
+	writeJSON(w, "Http::ResetPassword", http.StatusOK, PasswordResponse{Message: "Password Updated"})
+}
+
+// Represents Change Password Request
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword" example:"Secret123"` // Current Password
+	NewPassword     string `json:"newPassword" example:"Secret456"`     // New Password, 8-72 chars with a letter & a digit
+}
+
+// ChangePassword godoc
+//
+//	@Summary		Change Password API
+//	@Description	Changes password of the signed in User. Signs the user out everywhere else and returns new tokens for the current client.
+//	@Tags			auth
+//	@Accept			json
+//	@Param			input	body	ChangePasswordRequest	true	"Current & New Password"
+//	@Produce		json
+//	@Success		200	{object}	SigninResponse
+//	@Failure		400	{object}	internal.ErrorResponse	"Invalid JSON body or password"
+//	@Failure		401	{object}	internal.ErrorResponse	"Invalid Bearer Token or current password"
+//	@Failure		403	{object}	internal.ErrorResponse	"Authenticated with API Key"
+//	@Failure		429	{object}	internal.ErrorResponse	"Account locked after too many failed signins"
+//	@Header			429	{integer}	Retry-After				"Seconds until the account is unlocked"
+//	@Failure		500	{object}	internal.ErrorResponse	"Server error"
+//	@Router			/api/v1/auth/change-password [post]
+//	@Security		Bearer
+func (s *Server) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	var req ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		internal.APIError(w, "Http::ChangePassword", "Invalid JSON body", http.StatusBadRequest, err)
+		return
+	}
+
+	// Reload user with the password hash
+	user, err := s.UserService.FindUserByEmail(internal.UserFromContext(r.Context()).Email)
+	if err != nil {
+		internal.APIError(w, "Http::ChangePassword", "Token User not found", http.StatusUnauthorized, err)
+		return
+	}
+
+	// Wrong current passwords count as failed signins, so they can't be
+	// guessed here past the lockout
+	_, passwordErr := s.Passwords.Verify(user.Password, req.CurrentPassword)
+	if rejectLocked(w, "Http::ChangePassword", user) {
+		return
+	}
+	if passwordErr != nil {
+		s.recordFailedSignin(user)
+		internal.APIError(w, "Http::ChangePassword", "Invalid current password", http.StatusUnauthorized, passwordErr)
+		return
+	}
+	if err := internal.ValidatePassword(req.NewPassword, user.Email); err != nil {
+		internal.APIError(w, "Http::ChangePassword", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+	if req.NewPassword == req.CurrentPassword {
+		internal.APIError(w, "Http::ChangePassword", "new password must differ from the current one", http.StatusBadRequest, nil)
+		return
+	}
+
+	passwordHash, err := s.hashPassword(req.NewPassword)
+	if err != nil {
+		internal.APIError(w, "Http::ChangePassword", "Failed to hash password", http.StatusInternalServerError, err)
+		return
+	}
+	if err := s.setPassword(int(user.ID), passwordHash); err != nil {
+		internal.APIError(w, "Http::ChangePassword", "Couldn't update password", http.StatusInternalServerError, err)
+		return
+	}
+	s.resetFailedSignins("Http::ChangePassword", user)
+
+	// All sessions are revoked now, start a new one for the current client
+	s.completeSignin(w, r, "Http::ChangePassword", user)
+}
+
+// Updates password hash of the user & revokes all of the user's sessions
+// & tokens, including unused reset & magic links.
+func (s *Server) setPassword(userID int, passwordHash string) error {
+	if err := s.UserService.UpdateUserPassword(userID, passwordHash); err != nil {
+		return err
+	}
+	if err := s.UserTokenService.ExpireUserTokens(userID); err != nil {
+		return err
+	}
+	return s.SessionService.RevokeUserSessions(userID)
+}
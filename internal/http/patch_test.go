@@ -0,0 +1,183 @@
+package http
+
+import (
+	"go-api/internal"
+
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestApplyJSONPatch(t *testing.T) {
+	cases := []struct {
+		name       string
+		doc        string
+		patch      string
+		want       string // Patched document, empty if patch should fail
+		wantErr    bool
+		testFailed bool // Error should be errPatchTestFailed
+	}{
+		// add
+		{name: "add member", doc: `{"a":1}`, patch: `[{"op":"add","path":"/b","value":2}]`, want: `{"a":1,"b":2}`},
+		{name: "add replaces member", doc: `{"a":1}`, patch: `[{"op":"add","path":"/a","value":[1]}]`, want: `{"a":[1]}`},
+		{name: "add inserts into array", doc: `{"a":[1,3]}`, patch: `[{"op":"add","path":"/a/1","value":2}]`, want: `{"a":[1,2,3]}`},
+		{name: "add appends to array", doc: `{"a":[1]}`, patch: `[{"op":"add","path":"/a/-","value":2}]`, want: `{"a":[1,2]}`},
+		{name: "add to root", doc: `{"a":1}`, patch: `[{"op":"add","path":"","value":{"b":2}}]`, want: `{"b":2}`},
+		{name: "add null value", doc: `{}`, patch: `[{"op":"add","path":"/a","value":null}]`, want: `{"a":null}`},
+		{name: "add without parent", doc: `{}`, patch: `[{"op":"add","path":"/a/b","value":1}]`, wantErr: true},
+		{name: "add past array end", doc: `{"a":[1]}`, patch: `[{"op":"add","path":"/a/2","value":2}]`, wantErr: true},
+		{name: "add without value", doc: `{}`, patch: `[{"op":"add","path":"/a"}]`, wantErr: true},
+
+		// remove
+		{name: "remove member", doc: `{"a":1,"b":2}`, patch: `[{"op":"remove","path":"/a"}]`, want: `{"b":2}`},
+		{name: "remove array element", doc: `{"a":[1,2,3]}`, patch: `[{"op":"remove","path":"/a/1"}]`, want: `{"a":[1,3]}`},
+		{name: "remove missing member", doc: `{}`, patch: `[{"op":"remove","path":"/a"}]`, wantErr: true},
+		{name: "remove with leading zero index", doc: `{"a":[1,2]}`, patch: `[{"op":"remove","path":"/a/01"}]`, wantErr: true},
+		{name: "remove root", doc: `{"a":1}`, patch: `[{"op":"remove","path":""}]`, wantErr: true},
+
+		// replace
+		{name: "replace member", doc: `{"a":1}`, patch: `[{"op":"replace","path":"/a","value":2}]`, want: `{"a":2}`},
+		{name: "replace array element", doc: `{"a":[1,2]}`, patch: `[{"op":"replace","path":"/a/0","value":0}]`, want: `{"a":[0,2]}`},
+		{name: "replace root", doc: `{"a":1}`, patch: `[{"op":"replace","path":"","value":{"b":2}}]`, want: `{"b":2}`},
+		{name: "replace missing member", doc: `{}`, patch: `[{"op":"replace","path":"/a","value":1}]`, wantErr: true},
+
+		// move
+		{name: "move member", doc: `{"a":{"b":1},"c":{}}`, patch: `[{"op":"move","from":"/a/b","path":"/c/d"}]`, want: `{"a":{},"c":{"d":1}}`},
+		{name: "move array element", doc: `{"a":[1,2,3]}`, patch: `[{"op":"move","from":"/a/0","path":"/a/-"}]`, want: `{"a":[2,3,1]}`},
+		{name: "move onto itself", doc: `{"a":1}`, patch: `[{"op":"move","from":"/a","path":"/a"}]`, want: `{"a":1}`},
+		{name: "move into own child", doc: `{"a":{"b":{}}}`, patch: `[{"op":"move","from":"/a","path":"/a/b/c"}]`, wantErr: true},
+		{name: "move missing member", doc: `{}`, patch: `[{"op":"move","from":"/a","path":"/b"}]`, wantErr: true},
+
+		// copy
+		{name: "copy member", doc: `{"a":{"b":1}}`, patch: `[{"op":"copy","from":"/a","path":"/c"}]`, want: `{"a":{"b":1},"c":{"b":1}}`},
+		{name: "copy is independent", doc: `{"a":{"b":1}}`, patch: `[{"op":"copy","from":"/a","path":"/c"},{"op":"replace","path":"/c/b","value":2}]`, want: `{"a":{"b":1},"c":{"b":2}}`},
+		{name: "copy missing member", doc: `{}`, patch: `[{"op":"copy","from":"/a","path":"/b"}]`, wantErr: true},
+
+		// test
+		{name: "test passes", doc: `{"a":{"b":[1,"x"]}}`, patch: `[{"op":"test","path":"/a","value":{"b":[1,"x"]}}]`, want: `{"a":{"b":[1,"x"]}}`},
+		{name: "test fails", doc: `{"a":1}`, patch: `[{"op":"test","path":"/a","value":2}]`, wantErr: true, testFailed: true},
+		{name: "test fails on type", doc: `{"a":1}`, patch: `[{"op":"test","path":"/a","value":"1"}]`, wantErr: true, testFailed: true},
+		{name: "failing test aborts patch", doc: `{"a":1}`, patch: `[{"op":"replace","path":"/a","value":2},{"op":"test","path":"/a","value":1}]`, wantErr: true, testFailed: true},
+
+		// Escaped pointers (RFC 6901)
+		{name: "escaped slash", doc: `{"a/b":1}`, patch: `[{"op":"replace","path":"/a~1b","value":2}]`, want: `{"a/b":2}`},
+		{name: "escaped tilde", doc: `{"m~n":1}`, patch: `[{"op":"remove","path":"/m~0n"}]`, want: `{}`},
+		{name: "escaped tilde before one", doc: `{"~1":1}`, patch: `[{"op":"test","path":"/~01","value":1}]`, want: `{"~1":1}`},
+		{name: "empty key", doc: `{"":1}`, patch: `[{"op":"replace","path":"/","value":2}]`, want: `{"":2}`},
+
+		// Invalid patches
+		{name: "unknown operation", doc: `{}`, patch: `[{"op":"merge","path":"/a","value":1}]`, wantErr: true},
+		{name: "pointer without slash", doc: `{"a":1}`, patch: `[{"op":"remove","path":"a"}]`, wantErr: true},
+		{name: "not an array", doc: `{}`, patch: `{"op":"add","path":"/a","value":1}`, wantErr: true},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			var doc any
+			if err := json.Unmarshal([]byte(tt.doc), &doc); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := applyJSONPatch(doc, []byte(tt.patch))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("applyJSONPatch() = %v, want error", got)
+				}
+				if errors.Is(err, errPatchTestFailed) != tt.testFailed {
+					t.Fatalf("applyJSONPatch() error = %v, test failed %v", err, tt.testFailed)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyJSONPatch() error = %v", err)
+			}
+			assertJSON(t, got, tt.want)
+		})
+	}
+}
+
+func TestUserPatchByIDStatus(t *testing.T) {
+	cases := []struct {
+		name       string
+		patch      string
+		wantStatus int
+	}{
+		{name: "failing test", patch: `[{"op":"test","path":"/name","value":"Other"}]`, wantStatus: http.StatusConflict},
+		{name: "missing path", patch: `[{"op":"test","path":"/missing","value":1}]`, wantStatus: http.StatusBadRequest},
+		{name: "invalid syntax", patch: `[{"op":`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer(0)
+			s.UserService = &fakeUserService{users: []*internal.User{{ID: 1, Name: "Stub", Email: "stub.user@example.com"}}}
+
+			r := httptest.NewRequest(http.MethodPatch, "/1", strings.NewReader(tt.patch))
+			r.Header.Set("Content-Type", mediaTypeJSONPatch)
+			r.SetPathValue("id", "1")
+			rec := httptest.NewRecorder()
+			s.UserPatchByID(rec, r)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+		})
+	}
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	// Examples from RFC 7396 Appendix A
+	cases := []struct {
+		doc   string
+		patch string
+		want  string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.patch, func(t *testing.T) {
+			var doc any
+			if err := json.Unmarshal([]byte(tt.doc), &doc); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := applyMergePatch(doc, []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("applyMergePatch() error = %v", err)
+			}
+			assertJSON(t, got, tt.want)
+		})
+	}
+}
+
+// Fails the test if got doesn't equal the JSON document want.
+func assertJSON(t *testing.T, got any, want string) {
+	t.Helper()
+	var w any
+	if err := json.Unmarshal([]byte(want), &w); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, w) {
+		data, _ := json.Marshal(got)
+		t.Fatalf("got %s, want %s", data, want)
+	}
+}
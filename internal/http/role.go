@@ -0,0 +1,494 @@
+package http
+
+import (
+	"go-api/internal"
+	"go-api/internal/http/middlewares"
+
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Helper function for registering all role routes.
+func (s *Server) registerRoleRoutes(r *http.ServeMux) {
+	sm := http.NewServeMux()
+
+	// Module Middlewares
+	stack := middlewares.CreateStack(
+		middlewares.Logging,
+		middlewares.RateLimiter,
+		middlewares.AllowCors,
+		s.IsAuthenticated,
+	)
+
+	sm.Handle("GET /", can(internal.PermissionRolesRead, s.RoleAll))
+	sm.Handle("GET /{id}", can(internal.PermissionRolesRead, s.RoleFindByID))
+	sm.Handle("POST /", can(internal.PermissionRolesWrite, s.RoleCreate))
+	sm.Handle("PUT /{id}", can(internal.PermissionRolesWrite, s.RoleUpdateByID))
+	sm.Handle("DELETE /{id}", can(internal.PermissionRolesWrite, s.RoleDeleteByID))
+	sm.Handle("GET /{id}/permissions", can(internal.PermissionRolesRead, s.RolePermissionAll))
+	sm.Handle("POST /{id}/permissions", can(internal.PermissionRolesWrite, s.RolePermissionGrant))
+	sm.Handle("DELETE /{id}/permissions/{permission}", can(internal.PermissionRolesWrite, s.RolePermissionRevoke))
+
+	r.Handle("/api/v1/roles/", stack(http.StripPrefix("/api/v1/roles", sm)))
+
+	// Permission vocabulary
+	pm := http.NewServeMux()
+	pm.Handle("GET /", can(internal.PermissionRolesRead, s.PermissionAll))
+
+	r.Handle("/api/v1/permissions/", stack(http.StripPrefix("/api/v1/permissions", pm)))
+}
+
+// Represents Role Create / Update Request
+type RoleRequest struct {
+	Name       string `json:"name" example:"manager"`     // Role's name
+	RequireMFA bool   `json:"requireMfa" example:"false"` // Require two-factor authentication for users having the role
+}
+
+// RoleAll godoc
+//
+//	@Summary		Fetch All Roles
+//	@Description	Fetch All Roles
+//	@Tags			roles
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	[]internal.Role
+//	@Failure		401	{object}	internal.ErrorResponse	"Invalid Bearer Token"
+//	@Failure		500	{object}	internal.ErrorResponse	"Server error"
+//	@Router			/api/v1/roles [get]
+//	@Security		Bearer
+func (s *Server) RoleAll(w http.ResponseWriter, r *http.Request) {
+	roles, err := s.RoleService.FindRoles()
+	if err != nil {
+		internal.APIError(w, "Http::RoleAll", "Couldn't find roles", http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, "Http::RoleAll", http.StatusOK, roles)
+}
+
+// RoleFindByID godoc
+//
+//	@Summary		Fetch Role by ID
+//	@Description	Fetch Role by ID
+//	@Tags			roles
+//	@Accept			json
+//	@Param			id	path	integer	true	"Role ID"	default(1)
+//	@Produce		json
+//	@Success		200	{object}	internal.Role
+//	@Failure		401	{object}	internal.ErrorResponse	"Invalid Bearer Token"
+//	@Failure		404	{object}	internal.ErrorResponse	"Role not found"
+//	@Failure		500	{object}	internal.ErrorResponse	"Server error"
+//	@Router			/api/v1/roles/{id} [get]
+//	@Security		Bearer
+func (s *Server) RoleFindByID(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		internal.APIError(w, "Http::RoleFindByID", "Invalid Role ID", http.StatusNotFound, err)
+		return
+	}
+
+	role, err := s.RoleService.FindRoleByID(id)
+	if err != nil {
+		internal.APIError(w, "Http::RoleFindByID", "Role not found", errorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, "Http::RoleFindByID", http.StatusOK, role)
+}
+
+// RoleCreate godoc
+//
+//	@Summary		Create Role
+//	@Description	Create Role
+//	@Tags			roles
+//	@Accept			json
+//	@Param			input	body	RoleRequest	true	"Role Details"
+//	@Produce		json
+//	@Success		201	{object}	internal.Role
+//	@Header			201	{string}	Location				"URL of created Role"
+//	@Failure		400	{object}	internal.ErrorResponse	"Invalid JSON body"
+//	@Failure		401	{object}	internal.ErrorResponse	"Invalid Bearer Token"
+//	@Failure		403	{object}	internal.ErrorResponse	"Missing permission"
+//	@Failure		409	{object}	internal.ErrorResponse	"Role already exists"
+//	@Failure		500	{object}	internal.ErrorResponse	"Server error"
+//	@Router			/api/v1/roles [post]
+//	@Security		Bearer
+func (s *Server) RoleCreate(w http.ResponseWriter, r *http.Request) {
+	req, err := decodeRoleRequest(r)
+	if err != nil {
+		internal.APIError(w, "Http::RoleCreate", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	role := internal.Role{Name: req.Name, RequireMFA: req.RequireMFA}
+	if err := s.RoleService.CreateRole(&role); err != nil {
+		internal.APIError(w, "Http::RoleCreate", "Role already exists", errorStatus(err), err)
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/roles/"+strconv.Itoa(int(role.ID)))
+	writeJSON(w, "Http::RoleCreate", http.StatusCreated, role)
+}
+
+// RoleUpdateByID godoc
+//
+//	@Summary		Update Role by ID
+//	@Description	Replace Role's name & MFA requirement by ID
+//	@Tags			roles
+//	@Accept			json
+//	@Param			id		path	integer		true	"Role ID"	default(1)
+//	@Param			input	body	RoleRequest	true	"Role Details"
+//	@Produce		json
+//	@Success		200	{object}	internal.Role
+//	@Failure		400	{object}	internal.ErrorResponse	"Invalid JSON body"
+//	@Failure		401	{object}	internal.ErrorResponse	"Invalid Bearer Token"
+//	@Failure		403	{object}	internal.ErrorResponse	"Missing permission"
+//	@Failure		404	{object}	internal.ErrorResponse	"Role not found"
+//	@Failure		409	{object}	internal.ErrorResponse	"Role already exists"
+//	@Failure		500	{object}	internal.ErrorResponse	"Server error"
+//	@Router			/api/v1/roles/{id} [put]
+//	@Security		Bearer
+func (s *Server) RoleUpdateByID(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		internal.APIError(w, "Http::RoleUpdateByID", "Invalid Role ID", http.StatusNotFound, err)
+		return
+	}
+
+	req, err := decodeRoleRequest(r)
+	if err != nil {
+		internal.APIError(w, "Http::RoleUpdateByID", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	role, err := s.RoleService.UpdateRole(id, internal.RoleUpdate{Name: &req.Name, RequireMFA: &req.RequireMFA})
+	if err != nil {
+		switch {
+		case errors.Is(err, internal.ErrConflict):
+			internal.APIError(w, "Http::RoleUpdateByID", "Role already exists", http.StatusConflict, err)
+		default:
+			internal.APIError(w, "Http::RoleUpdateByID", "Role not found", errorStatus(err), err)
+		}
+		return
+	}
+
+	writeJSON(w, "Http::RoleUpdateByID", http.StatusOK, role)
+}
+
+// RoleDeleteByID godoc
+//
+//	@Summary		Delete Role by ID
+//	@Description	Delete Role by ID along with its assignments to Users
+//	@Tags			roles
+//	@Accept			json
+//	@Param			id	path	integer	true	"Role ID"	default(1)
+//	@Produce		json
+//	@Success		204
+//	@Failure		401	{object}	internal.ErrorResponse	"Invalid Bearer Token"
+//	@Failure		403	{object}	internal.ErrorResponse	"Missing permission"
+//	@Failure		404	{object}	internal.ErrorResponse	"Role not found"
+//	@Failure		500	{object}	internal.ErrorResponse	"Server error"
+//	@Router			/api/v1/roles/{id} [delete]
+//	@Security		Bearer
+func (s *Server) RoleDeleteByID(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		internal.APIError(w, "Http::RoleDeleteByID", "Invalid Role ID", http.StatusNotFound, err)
+		return
+	}
+
+	if err := s.RoleService.DeleteRole(id); err != nil {
+		internal.APIError(w, "Http::RoleDeleteByID", "Role not found", errorStatus(err), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PermissionAll godoc
+//
+//	@Summary		Fetch All Permissions
+//	@Description	Fetch names of all Permissions which can be granted to Roles. Permissions follow `module:action` vocabulary.
+//	@Tags			roles
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	[]string
+//	@Failure		401	{object}	internal.ErrorResponse	"Invalid Bearer Token"
+//	@Failure		403	{object}	internal.ErrorResponse	"Missing permission"
+//	@Failure		500	{object}	internal.ErrorResponse	"Server error"
+//	@Router			/api/v1/permissions [get]
+//	@Security		Bearer
+func (s *Server) PermissionAll(w http.ResponseWriter, r *http.Request) {
+	permissions, err := s.RoleService.FindPermissions()
+	if err != nil {
+		internal.APIError(w, "Http::PermissionAll", "Couldn't find permissions", http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, "Http::PermissionAll", http.StatusOK, permissions)
+}
+
+// Represents Permission Grant Request
+type RolePermissionRequest struct {
+	Permission string `json:"permission" example:"users:read"` // Permission to grant
+}
+
+// RolePermissionAll godoc
+//
+//	@Summary		Fetch Permissions of Role
+//	@Description	Fetch Permissions granted to Role
+//	@Tags			roles
+//	@Accept			json
+//	@Param			id	path	integer	true	"Role ID"	default(1)
+//	@Produce		json
+//	@Success		200	{object}	[]string
+//	@Failure		401	{object}	internal.ErrorResponse	"Invalid Bearer Token"
+//	@Failure		403	{object}	internal.ErrorResponse	"Missing permission"
+//	@Failure		404	{object}	internal.ErrorResponse	"Role not found"
+//	@Failure		500	{object}	internal.ErrorResponse	"Server error"
+//	@Router			/api/v1/roles/{id}/permissions [get]
+//	@Security		Bearer
+func (s *Server) RolePermissionAll(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		internal.APIError(w, "Http::RolePermissionAll", "Invalid Role ID", http.StatusNotFound, err)
+		return
+	}
+
+	permissions, err := s.RoleService.FindRolePermissions(id)
+	if err != nil {
+		internal.APIError(w, "Http::RolePermissionAll", "Role not found", errorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, "Http::RolePermissionAll", http.StatusOK, permissions)
+}
+
+// RolePermissionGrant godoc
+//
+//	@Summary		Grant Permission to Role
+//	@Description	Grant Permission to Role, does nothing if already granted
+//	@Tags			roles
+//	@Accept			json
+//	@Param			id		path	integer					true	"Role ID"	default(1)
+//	@Param			input	body	RolePermissionRequest	true	"Permission to grant"
+//	@Produce		json
+//	@Success		200	{object}	[]string
+//	@Failure		400	{object}	internal.ErrorResponse	"Invalid JSON body"
+//	@Failure		401	{object}	internal.ErrorResponse	"Invalid Bearer Token"
+//	@Failure		403	{object}	internal.ErrorResponse	"Missing permission or Permission not held by caller"
+//	@Failure		404	{object}	internal.ErrorResponse	"Role or Permission not found"
+//	@Failure		500	{object}	internal.ErrorResponse	"Server error"
+//	@Router			/api/v1/roles/{id}/permissions [post]
+//	@Security		Bearer
+func (s *Server) RolePermissionGrant(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		internal.APIError(w, "Http::RolePermissionGrant", "Invalid Role ID", http.StatusNotFound, err)
+		return
+	}
+
+	var req RolePermissionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		internal.APIError(w, "Http::RolePermissionGrant", "Invalid JSON body", http.StatusBadRequest, err)
+		return
+	}
+
+	// Granting a permission the caller doesn't hold to a role of the caller
+	// would escalate the caller's own permissions
+	if !internal.UserFromContext(r.Context()).HasPermission(req.Permission) {
+		internal.APIError(w, "Http::RolePermissionGrant", "Missing permission "+req.Permission, http.StatusForbidden, nil)
+		return
+	}
+
+	if err := s.RoleService.GrantPermission(id, req.Permission); err != nil {
+		internal.APIError(w, "Http::RolePermissionGrant", "Role or Permission not found", errorStatus(err), err)
+		return
+	}
+
+	// Respond with updated permissions of the role
+	s.RolePermissionAll(w, r)
+}
+
+// RolePermissionRevoke godoc
+//
+//	@Summary		Revoke Permission from Role
+//	@Description	Revoke Permission from Role
+//	@Tags			roles
+//	@Accept			json
+//	@Param			id			path	integer	true	"Role ID"		default(1)
+//	@Param			permission	path	string	true	"Permission"	default(users:read)
+//	@Produce		json
+//	@Success		204
+//	@Failure		401	{object}	internal.ErrorResponse	"Invalid Bearer Token"
+//	@Failure		403	{object}	internal.ErrorResponse	"Missing permission"
+//	@Failure		404	{object}	internal.ErrorResponse	"Permission not granted to Role"
+//	@Failure		500	{object}	internal.ErrorResponse	"Server error"
+//	@Router			/api/v1/roles/{id}/permissions/{permission} [delete]
+//	@Security		Bearer
+func (s *Server) RolePermissionRevoke(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		internal.APIError(w, "Http::RolePermissionRevoke", "Invalid Role ID", http.StatusNotFound, err)
+		return
+	}
+
+	if err := s.RoleService.RevokePermission(id, r.PathValue("permission")); err != nil {
+		internal.APIError(w, "Http::RolePermissionRevoke", "Permission not granted to Role", errorStatus(err), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Represents Role Assignment Request
+type UserRoleRequest struct {
+	RoleID int `json:"roleId" example:"2"` // ID of Role to assign
+}
+
+// UserRoleAll godoc
+//
+//	@Summary		Fetch Roles of User
+//	@Description	Fetch Roles assigned to User
+//	@Tags			users
+//	@Accept			json
+//	@Param			id	path	integer	true	"User ID"	default(1)
+//	@Produce		json
+//	@Success		200	{object}	[]internal.Role
+//	@Failure		401	{object}	internal.ErrorResponse	"Invalid Bearer Token"
+//	@Failure		404	{object}	internal.ErrorResponse	"User not found"
+//	@Failure		500	{object}	internal.ErrorResponse	"Server error"
+//	@Router			/api/v1/users/{id}/roles [get]
+//	@Security		Bearer
+func (s *Server) UserRoleAll(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		internal.APIError(w, "Http::UserRoleAll", "Invalid User ID", http.StatusNotFound, err)
+		return
+	}
+
+	roles, err := s.RoleService.FindUserRoles(id)
+	if err != nil {
+		internal.APIError(w, "Http::UserRoleAll", "User not found", errorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, "Http::UserRoleAll", http.StatusOK, roles)
+}
+
+// UserRoleAssign godoc
+//
+//	@Summary		Assign Role to User
+//	@Description	Assign Role to User, does nothing if already assigned
+//	@Tags			users
+//	@Accept			json
+//	@Param			id		path	integer			true	"User ID"	default(1)
+//	@Param			input	body	UserRoleRequest	true	"Role to assign"
+//	@Produce		json
+//	@Success		200	{object}	[]internal.Role
+//	@Failure		400	{object}	internal.ErrorResponse	"Invalid JSON body"
+//	@Failure		401	{object}	internal.ErrorResponse	"Invalid Bearer Token"
+//	@Failure		403	{object}	internal.ErrorResponse	"Missing permission or User/Role has more permissions"
+//	@Failure		404	{object}	internal.ErrorResponse	"User or Role not found"
+//	@Failure		500	{object}	internal.ErrorResponse	"Server error"
+//	@Router			/api/v1/users/{id}/roles [post]
+//	@Security		Bearer
+func (s *Server) UserRoleAssign(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		internal.APIError(w, "Http::UserRoleAssign", "Invalid User ID", http.StatusNotFound, err)
+		return
+	}
+
+	var req UserRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		internal.APIError(w, "Http::UserRoleAssign", "Invalid JSON body", http.StatusBadRequest, err)
+		return
+	}
+	if !s.authorizeRoleAssignment(w, r, "Http::UserRoleAssign", id, req.RoleID) {
+		return
+	}
+
+	if err := s.RoleService.AssignRole(id, req.RoleID); err != nil {
+		internal.APIError(w, "Http::UserRoleAssign", "User or Role not found", errorStatus(err), err)
+		return
+	}
+
+	// Respond with updated roles of the user
+	s.UserRoleAll(w, r)
+}
+
+// UserRoleRevoke godoc
+//
+//	@Summary		Revoke Role from User
+//	@Description	Revoke Role from User
+//	@Tags			users
+//	@Accept			json
+//	@Param			id		path	integer	true	"User ID"	default(1)
+//	@Param			roleId	path	integer	true	"Role ID"	default(2)
+//	@Produce		json
+//	@Success		204
+//	@Failure		401	{object}	internal.ErrorResponse	"Invalid Bearer Token"
+//	@Failure		403	{object}	internal.ErrorResponse	"Missing permission or User/Role has more permissions"
+//	@Failure		404	{object}	internal.ErrorResponse	"Role not assigned to User"
+//	@Failure		500	{object}	internal.ErrorResponse	"Server error"
+//	@Router			/api/v1/users/{id}/roles/{roleId} [delete]
+//	@Security		Bearer
+func (s *Server) UserRoleRevoke(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		internal.APIError(w, "Http::UserRoleRevoke", "Invalid User ID", http.StatusNotFound, err)
+		return
+	}
+	roleID, err := strconv.Atoi(r.PathValue("roleId"))
+	if err != nil {
+		internal.APIError(w, "Http::UserRoleRevoke", "Invalid Role ID", http.StatusNotFound, err)
+		return
+	}
+	if !s.authorizeRoleAssignment(w, r, "Http::UserRoleRevoke", id, roleID) {
+		return
+	}
+
+	if err := s.RoleService.RevokeRole(id, roleID); err != nil {
+		internal.APIError(w, "Http::UserRoleRevoke", "Role not assigned to User", errorStatus(err), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Rejects with 403 unless the caller holds every permission of both the
+// user & the role, so (un)assigning the role can't gain the caller any
+// permission. Returns false if a response was written.
+func (s *Server) authorizeRoleAssignment(w http.ResponseWriter, r *http.Request, module string, userID int, roleID int) bool {
+	if !s.authorizeUserTarget(w, r, module, userID) {
+		return false
+	}
+
+	permissions, err := s.RoleService.FindRolePermissions(roleID)
+	if err != nil {
+		internal.APIError(w, module, "Role not found", errorStatus(err), err)
+		return false
+	}
+	if !internal.UserFromContext(r.Context()).HasPermissions(permissions) {
+		internal.APIError(w, module, "Role has permissions you don't have", http.StatusForbidden, nil)
+		return false
+	}
+	return true
+}
+
+// Parses & validates the role request body.
+func decodeRoleRequest(r *http.Request) (RoleRequest, error) {
+	var req RoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return req, errors.New("Invalid JSON body")
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return req, errors.New("name is required")
+	}
+	return req, nil
+}
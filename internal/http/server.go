@@ -2,12 +2,17 @@ package http
 
 import (
 	"go-api/internal"
+	"go-api/internal/mail"
+	"go-api/internal/memory"
+	"go-api/internal/password"
 
 	_ "go-api/docs"
 
 	"context"
+	"crypto/rand"
 	"net/http"
 	"strconv"
+	"sync"
 	"time"
 )
 
@@ -28,9 +33,53 @@ type Server struct {
 	Port int
 
 	// Services used by the various HTTP routes.
-	UserService internal.UserService
+	UserService         internal.UserService
+	RoleService         internal.RoleService
+	RefreshTokenService internal.RefreshTokenService
+	UserTokenService    internal.UserTokenService
+	MFAService          internal.MFAService
+	APIKeyService       internal.APIKeyService
+	IdentityService     internal.IdentityService
+	SessionService      internal.SessionService
+	AuditLogService     internal.AuditLogService
 
-	BlacklistedToken map[string]bool
+	// Mailer for sending mails to users. Logs mails by default.
+	Mailer internal.Mailer
+
+	// Base URL of the frontend app, used in links mailed to users.
+	AppURL string
+
+	// OpenID Connect providers users can signin with, by name.
+	OIDCProviders map[string]*OIDCProvider
+
+	// Issuer shown by authenticator apps for TOTP secrets.
+	MFAIssuer string
+
+	// Secret for signing opaque values handed to clients, like pagination
+	// cursors. Random by default, so such values don't survive restarts.
+	SigningSecret []byte
+
+	// Keys for signing & verifying JWT Access Tokens. Ephemeral by default,
+	// so issued tokens don't survive restarts.
+	Keys *KeySet
+
+	// Lifetime of issued Access & Refresh Tokens.
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+
+	// Cookie auth mode for browser frontends. Disabled by default.
+	CookieAuth CookieAuthConfig
+
+	// Policy for hashing passwords. Hashes of other algorithms or outdated
+	// parameters still verify & get rehashed on signin. Bcrypt by default.
+	Passwords *password.Policy
+
+	// Hash of a random password, see dummyPasswordHash.
+	dummyHashOnce sync.Once
+	dummyHash     string
+
+	// Store of revoked Access Tokens. In-memory by default.
+	TokenRevocationStore internal.TokenRevocationStore
 }
 
 // NewServer returns a new instance of Server.
@@ -45,8 +94,24 @@ func NewServer(port int) *Server {
 			Addr:    ":" + strconv.Itoa(port),
 			Handler: router,
 		},
-		BlacklistedToken: make(map[string]bool),
+		TokenRevocationStore: memory.NewTokenRevocationStore(),
+		Mailer:               mail.NewLogMailer(),
+		AppURL:               "http://localhost:" + strconv.Itoa(port),
+		MFAIssuer:            "Kushan GO",
+		SigningSecret:        make([]byte, 32),
+		AccessTokenTTL:       15 * time.Minute,
+		RefreshTokenTTL:      30 * 24 * time.Hour,
+		CookieAuth:           CookieAuthConfig{Secure: true, SameSite: http.SameSiteLaxMode},
+		Passwords:            password.NewPolicy(password.BcryptHasher{}),
+	}
+	if _, err := rand.Read(server.SigningSecret); err != nil {
+		panic(err)
 	}
+	key, err := GenerateSigningKey()
+	if err != nil {
+		panic(err)
+	}
+	server.Keys = NewKeySet(key)
 
 	// ?
 	server.Router = router
@@ -54,6 +119,9 @@ func NewServer(port int) *Server {
 	// Setup Base Routes
 	server.registerAuthRoutes(router)
 	server.registerUserRoutes(router)
+	server.registerRoleRoutes(router)
+	server.registerAPIKeyRoutes(router)
+	server.registerJWKSRoutes(router)
 
 	// Load Swagger Doc
 	server.loadSwagger()
@@ -61,6 +129,12 @@ func NewServer(port int) *Server {
 	return server
 }
 
+// MaxTokenTTL returns the longest lifetime of tokens signed by Keys. A key
+// rotated out must keep verifying for this long after its last use.
+func (s *Server) MaxTokenTTL() time.Duration {
+	return max(s.AccessTokenTTL, mfaChallengeTTL)
+}
+
 // Close gracefully shuts down the server.
 func (s *Server) Close() error {
 	ctx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
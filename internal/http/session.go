@@ -0,0 +1,115 @@
+package http
+
+import (
+	"go-api/internal"
+
+	"net"
+	"net/http"
+	"time"
+)
+
+const (
+	sessionTouchInterval   = time.Minute // Last seen time of sessions is updated at most this often
+	sessionUserAgentMaxLen = 512         // User Agents are cut to this many bytes
+)
+
+// Helper function for registering session routes on the auth module mux.
+func (s *Server) registerSessionRoutes(sm *http.ServeMux) {
+	authenticated := func(h http.HandlerFunc) http.Handler {
+		return s.IsAuthenticated(requireAccessToken(forbidImpersonation(h)))
+	}
+
+	sm.Handle("GET /sessions", authenticated(s.SessionAll))
+	sm.Handle("DELETE /sessions", authenticated(s.SessionDeleteAll))
+	sm.Handle("DELETE /sessions/{id}", authenticated(s.SessionDeleteByID))
+}
+
+// SessionAll godoc
+//
+//	@Summary		Fetch own Sessions
+//	@Description	Fetch active sessions of the signed in User, most recently seen first. The session of the request is marked current.
+//	@Tags			auth
+//	@Produce		json
+//	@Success		200	{object}	[]internal.Session
+//	@Failure		401	{object}	internal.ErrorResponse	"Invalid Bearer Token"
+//	@Failure		403	{object}	internal.ErrorResponse	"Authenticated with API Key"
+//	@Failure		500	{object}	internal.ErrorResponse	"Server error"
+//	@Router			/api/v1/auth/sessions [get]
+//	@Security		Bearer
+func (s *Server) SessionAll(w http.ResponseWriter, r *http.Request) {
+	sessions, err := s.SessionService.FindUserSessions(int(internal.UserIDFromContext(r.Context())))
+	if err != nil {
+		internal.APIError(w, "Http::SessionAll", "Couldn't find sessions", http.StatusInternalServerError, err)
+		return
+	}
+
+	if current := internal.SessionFromContext(r.Context()); current != nil {
+		for _, session := range sessions {
+			session.Current = session.ID == current.ID
+		}
+	}
+
+	writeJSON(w, "Http::SessionAll", http.StatusOK, sessions)
+}
+
+// SessionDeleteByID godoc
+//
+//	@Summary		Revoke Session by ID
+//	@Description	Signs the session out: its Refresh Tokens are revoked & its Access Tokens are rejected right away.
+//	@Tags			auth
+//	@Param			id	path	string	true	"Session ID"
+//	@Success		204
+//	@Failure		401	{object}	internal.ErrorResponse	"Invalid Bearer Token"
+//	@Failure		403	{object}	internal.ErrorResponse	"Authenticated with API Key"
+//	@Failure		404	{object}	internal.ErrorResponse	"Session not found"
+//	@Failure		500	{object}	internal.ErrorResponse	"Server error"
+//	@Router			/api/v1/auth/sessions/{id} [delete]
+//	@Security		Bearer
+func (s *Server) SessionDeleteByID(w http.ResponseWriter, r *http.Request) {
+	if err := s.SessionService.RevokeSession(int(internal.UserIDFromContext(r.Context())), r.PathValue("id")); err != nil {
+		internal.APIError(w, "Http::SessionDeleteByID", "Session not found", errorStatus(err), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SessionDeleteAll godoc
+//
+//	@Summary		Sign out everywhere
+//	@Description	Revokes all sessions of the signed in User, including the current one.
+//	@Tags			auth
+//	@Success		204
+//	@Failure		401	{object}	internal.ErrorResponse	"Invalid Bearer Token"
+//	@Failure		403	{object}	internal.ErrorResponse	"Authenticated with API Key"
+//	@Failure		500	{object}	internal.ErrorResponse	"Server error"
+//	@Router			/api/v1/auth/sessions [delete]
+//	@Security		Bearer
+func (s *Server) SessionDeleteAll(w http.ResponseWriter, r *http.Request) {
+	if err := s.SessionService.RevokeUserSessions(int(internal.UserIDFromContext(r.Context()))); err != nil {
+		internal.APIError(w, "Http::SessionDeleteAll", "Couldn't revoke sessions", http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Returns IP Address of the client, without port.
+func clientIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return ip
+}
+
+// Cuts s to at most n bytes, without splitting a UTF-8 character.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && s[n]&0xC0 == 0x80 {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,60 @@
+package http
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// Error returned when a signed value is malformed or its signature doesn't match
+var errInvalidSignature = errors.New("invalid signature")
+
+// Purposes of signed values. The purpose is part of the signature, so a value
+// signed for one purpose doesn't verify for another.
+const (
+	signPurposeCursor    = "cursor"     // Pagination cursors
+	signPurposeOIDCState = "oidc_state" // State of OIDC signin in progress
+	signPurposeCSRF      = "csrf"       // CSRF tokens of sessions
+)
+
+// Encodes v as an opaque string: base64url(json) + "." + base64url(HMAC-SHA256).
+// Used for values handed to clients which must come back untampered.
+func signValue(secret []byte, purpose string, v any) (string, error) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(signature(secret, purpose, encoded)), nil
+}
+
+// Verifies the signature of a string from signValue() for the same purpose
+// and decodes it into v.
+func verifyValue(secret []byte, purpose string, signed string, v any) error {
+	encoded, sig, ok := strings.Cut(signed, ".")
+	if !ok {
+		return errInvalidSignature
+	}
+	got, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(got, signature(secret, purpose, encoded)) {
+		return errInvalidSignature
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return errInvalidSignature
+	}
+	return json.Unmarshal(payload, v)
+}
+
+// HMAC-SHA256 of the data for the purpose. Purposes never contain NUL, so
+// the input is unambiguous.
+func signature(secret []byte, purpose string, data string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(purpose))
+	mac.Write([]byte{0})
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,25 @@
+package http
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSignedValuePurpose(t *testing.T) {
+	secret := []byte("secret")
+	signed, err := signValue(secret, signPurposeCursor, map[string]string{"id": "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var v map[string]string
+	if err := verifyValue(secret, signPurposeCursor, signed, &v); err != nil || v["id"] != "1" {
+		t.Fatalf("verifyValue() = %v, %v; want id 1", v, err)
+	}
+	if err := verifyValue(secret, signPurposeOIDCState, signed, &v); !errors.Is(err, errInvalidSignature) {
+		t.Fatalf("verifyValue() for other purpose error = %v, want %v", err, errInvalidSignature)
+	}
+	if err := verifyValue([]byte("other"), signPurposeCursor, signed, &v); !errors.Is(err, errInvalidSignature) {
+		t.Fatalf("verifyValue() with other secret error = %v, want %v", err, errInvalidSignature)
+	}
+}
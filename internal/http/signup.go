@@ -0,0 +1,204 @@
+package http
+
+import (
+	"go-api/internal"
+
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Validity of email verification links
+const emailVerificationTTL = 24 * time.Hour
+
+// Signup godoc
+//
+//	@Summary		Sign Up API
+//	@Description	Registers a new User with unverified email & mails the verification link. User can signin once the email is verified.
+//	@Tags			auth
+//	@Accept			json
+//	@Param			input	body	UserCreateRequest	true	"Signup Details"
+//	@Produce		json
+//	@Success		201	{object}	internal.User
+//	@Failure		400	{object}	internal.ErrorResponse	"Invalid JSON body"
+//	@Failure		409	{object}	internal.ErrorResponse	"Email already exists"
+//	@Failure		500	{object}	internal.ErrorResponse	"Server error"
+//	@Router			/api/v1/auth/signup [post]
+func (s *Server) Signup(w http.ResponseWriter, r *http.Request) {
+	// Parse signup request into object
+	var req UserCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		internal.APIError(w, "Http::Signup", "Invalid JSON body", http.StatusBadRequest, err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		internal.APIError(w, "Http::Signup", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	// Hash the password
+	passwordHash, err := s.hashPassword(req.Password)
+	if err != nil {
+		internal.APIError(w, "Http::Signup", "Failed to hash password", http.StatusInternalServerError, err)
+		return
+	}
+
+	user := internal.User{
+		Name:     strings.TrimSpace(req.Name),
+		Email:    strings.TrimSpace(req.Email),
+		Password: passwordHash,
+	}
+	if err := s.UserService.CreateUser(&user); err != nil {
+		if errors.Is(err, internal.ErrConflict) {
+			internal.APIError(w, "Http::Signup", "Email already exists", http.StatusConflict, err)
+			return
+		}
+		internal.APIError(w, "Http::Signup", "Failed to create user", http.StatusInternalServerError, err)
+		return
+	}
+
+	// Mail the verification link. User is already created, so a failure is
+	// only logged.
+	s.sendEmailVerification("Http::Signup", &user)
+
+	writeJSON(w, "Http::Signup", http.StatusCreated, user)
+}
+
+// Mails email verification link to the user, logging failures.
+func (s *Server) sendEmailVerification(module string, user *internal.User) {
+	token, err := s.issueUserToken(user, internal.UserTokenEmailVerification, emailVerificationTTL)
+	if err == nil {
+		err = s.Mailer.SendMail(&internal.Mail{
+			To:      user.Email,
+			Subject: "Verify your email",
+			Body: "Hi " + user.Name + ",\n\n" +
+				"Please verify your email by opening the link below. It is valid for 24 hours.\n\n" +
+				s.AppURL + "/verify-email?token=" + url.QueryEscape(token) + "\n",
+		})
+	}
+	if err != nil {
+		internal.Error(module, "Failed to send verification mail", err)
+	}
+}
+
+// Represents Email Verification Request
+type VerifyEmailRequest struct {
+	Token string `json:"token" example:"kKxVbHn0Ry0Ku2pV5Wm1Yf3b2c5ZV7H9HnRkq1xTzJQ"` // Token from the verification link
+}
+
+// Represents Email Verification Response
+type VerifyEmailResponse struct {
+	Message string `json:"message" example:"Email Verified"` // Verification Message
+}
+
+// VerifyEmail godoc
+//
+//	@Summary		Verify Email API
+//	@Description	Verifies User's email with the single use token from the verification link
+//	@Tags			auth
+//	@Accept			json
+//	@Param			input	body	VerifyEmailRequest	true	"Verification Token"
+//	@Produce		json
+//	@Success		200	{object}	VerifyEmailResponse
+//	@Failure		400	{object}	internal.ErrorResponse	"Invalid or expired token"
+//	@Failure		500	{object}	internal.ErrorResponse	"Server error"
+//	@Router			/api/v1/auth/verify-email [post]
+func (s *Server) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req VerifyEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" {
+		internal.APIError(w, "Http::VerifyEmail", "Invalid JSON body", http.StatusBadRequest, err)
+		return
+	}
+
+	t, err := s.UserTokenService.ConsumeUserToken(internal.UserTokenEmailVerification, hashToken(req.Token))
+	if err != nil {
+		if errors.Is(err, internal.ErrNotFound) {
+			internal.APIError(w, "Http::VerifyEmail", "Invalid or expired token", http.StatusBadRequest, err)
+			return
+		}
+		internal.APIError(w, "Http::VerifyEmail", "Couldn't verify token", http.StatusInternalServerError, err)
+		return
+	}
+
+	if err := s.UserService.VerifyUserEmail(int(t.UserID)); err != nil {
+		if errors.Is(err, internal.ErrNotFound) {
+			internal.APIError(w, "Http::VerifyEmail", "Invalid or expired token", http.StatusBadRequest, err)
+			return
+		}
+		internal.APIError(w, "Http::VerifyEmail", "Couldn't verify email", http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, "Http::VerifyEmail", http.StatusOK, VerifyEmailResponse{Message: "Email Verified"})
+}
+
+// Issues a single use token of given purpose for the user, valid for ttl.
+// Returns the token to be sent to the user.
+func (s *Server) issueUserToken(user *internal.User, purpose string, ttl time.Duration) (string, error) {
+	token, err := generateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	err = s.UserTokenService.CreateUserToken(&internal.UserToken{
+		UserID:    user.ID,
+		Purpose:   purpose,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// Represents Resend Verification Request
+type ResendVerificationRequest struct {
+	Email string `json:"email" example:"ganesh@dwij.in"` // Email Address of the account
+}
+
+// ResendVerification godoc
+//
+//	@Summary		Resend Email Verification API
+//	@Description	Mails a new verification link if the email is registered & not verified yet. Response is the same either way, so it doesn't reveal registered emails.
+//	@Tags			auth
+//	@Accept			json
+//	@Param			input	body	ResendVerificationRequest	true	"Account Email"
+//	@Produce		json
+//	@Success		202	{object}	VerifyEmailResponse
+//	@Failure		400	{object}	internal.ErrorResponse	"Invalid JSON body"
+//	@Router			/api/v1/auth/verify-email/resend [post]
+func (s *Server) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	var req ResendVerificationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		internal.APIError(w, "Http::ResendVerification", "Invalid JSON body", http.StatusBadRequest, err)
+		return
+	}
+
+	// Lookup & mailing happen in background, so response time doesn't
+	// reveal whether the email is registered either
+	go s.resendEmailVerification(strings.TrimSpace(req.Email))
+
+	writeJSON(w, "Http::ResendVerification", http.StatusAccepted, VerifyEmailResponse{
+		Message: "If the email is registered and not verified yet, a verification link has been sent to it",
+	})
+}
+
+// Mails a new verification link to the user having given email, if any and
+// not verified yet.
+func (s *Server) resendEmailVerification(email string) {
+	user, err := s.UserService.FindUserByEmail(email)
+	if err != nil {
+		if !errors.Is(err, internal.ErrNotFound) {
+			internal.Error("Http::ResendVerification", "Couldn't find user", err)
+		}
+		return
+	}
+	if user.EmailVerifiedAt != nil {
+		return
+	}
+
+	s.sendEmailVerification("Http::ResendVerification", user)
+}
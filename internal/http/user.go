@@ -5,8 +5,14 @@ import (
 	"go-api/internal/http/middlewares"
 
 	"encoding/json"
+	"errors"
+	"io"
+	"mime"
 	"net/http"
+	"net/mail"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // Helper function for registering all user routes.
@@ -21,30 +27,55 @@ func (s *Server) registerUserRoutes(r *http.ServeMux) {
 		s.IsAuthenticated,
 	)
 
-	sm.HandleFunc("GET /", s.UserAll)
-	sm.HandleFunc("GET /{id}", s.UserFindByID)
-	sm.HandleFunc("POST /", s.UserCreate)
-	sm.HandleFunc("PUT /{id}", s.UserUpdateByID)
-	sm.HandleFunc("DELETE /{id}", s.UserDeleteByID)
+	sm.Handle("GET /", can(internal.PermissionUsersRead, s.UserAll))
+	sm.Handle("GET /{id}", can(internal.PermissionUsersRead, s.UserFindByID))
+	sm.Handle("POST /", can(internal.PermissionUsersWrite, s.UserCreate))
+	sm.Handle("PUT /{id}", can(internal.PermissionUsersWrite, s.UserUpdateByID))
+	sm.Handle("PATCH /{id}", can(internal.PermissionUsersWrite, s.UserPatchByID))
+	sm.Handle("DELETE /{id}", can(internal.PermissionUsersDelete, s.UserDeleteByID))
+	sm.Handle("POST /{id}/restore", can(internal.PermissionUsersDelete, s.UserRestoreByID))
+	sm.Handle("POST /{id}/unlock", can(internal.PermissionUsersWrite, s.UserUnlockByID))
+	sm.Handle("GET /{id}/roles", can(internal.PermissionRolesRead, s.UserRoleAll))
+	sm.Handle("POST /{id}/roles", can(internal.PermissionRolesWrite, s.UserRoleAssign))
+	sm.Handle("DELETE /{id}/roles/{roleId}", can(internal.PermissionRolesWrite, s.UserRoleRevoke))
 
 	r.Handle("/api/v1/users/", stack(http.StripPrefix("/api/v1/users", sm)))
 }
 
+// Represents a page of Users with pagination details
+type UserListResponse struct {
+	Items  []*internal.User `json:"items"`                                                     // Users in this page
+	Total  int              `json:"total" example:"57"`                                        // Total number of matching Users
+	Offset int              `json:"offset" example:"20"`                                       // Pagination Offset of this page
+	Limit  int              `json:"limit" example:"20"`                                        // Pagination Limit of this page
+	Next   string           `json:"next,omitempty" example:"/api/v1/users?limit=20&offset=40"` // URL of next page
+	Prev   string           `json:"prev,omitempty" example:"/api/v1/users?limit=20&offset=0"`  // URL of previous page
+
+	NextCursor string `json:"nextCursor,omitempty" example:"eyJ2IjpbMjBdfQ.c2lnbmF0dXJl"` // Cursor of next page for keyset pagination
+}
+
 // UserAll godoc
 //
 //	@Summary		Fetch All Users
-//	@Description	Fetch All Users
+//	@Description	Fetch paginated list of Users. Pagination links are also sent in RFC 8288 `Link` header.
+//	@Description	Supports offset pagination with `offset` & `limit`, and keyset pagination by passing `nextCursor` of previous page as `cursor`.
 //	@Tags			users
 //	@Accept			json
-//	@Param			id		query	integer	false	"Filter by User ID"
-//	@Param			name	query	string	false	"Filter by User Name"
-//	@Param			email	query	string	false	"Filter by User Email"
-//	@Param			offset	query	integer	false	"Pagination Offset"	default(0)
-//	@Param			limit	query	integer	false	"Pagination Limit"	default(20)
+//	@Param			id			query	integer	false	"Filter by User ID"
+//	@Param			name		query	string	false	"Filter by User Name"
+//	@Param			email		query	string	false	"Filter by User Email"
+//	@Param			withTrashed	query	boolean	false	"Include soft deleted Users (needs users:delete)"
+//	@Param			onlyTrashed	query	boolean	false	"Only soft deleted Users (needs users:delete)"
+//	@Param			sort		query	string	false	"Comma separated sort fields, prefix with `-` for descending order. Allowed fields: id, name, email, created_at, updated_at"	default(id)
+//	@Param			offset		query	integer	false	"Pagination Offset"																												default(0)
+//	@Param			limit		query	integer	false	"Pagination Limit"																												default(20)
+//	@Param			cursor		query	string	false	"Keyset Pagination Cursor from `nextCursor`, Offset is ignored when set"
 //	@Produce		json
-//	@Success		200	{object}	[]internal.User
-//	@Failure		400	{object}	internal.ErrorResponse	"Invalid JSON body"
+//	@Success		200	{object}	UserListResponse
+//	@Header			200	{string}	Link					"Pagination links (first, prev, next, last)"
+//	@Failure		400	{object}	internal.ErrorResponse	"Invalid sort or cursor"
 //	@Failure		401	{object}	internal.ErrorResponse	"Invalid Bearer Token"
+//	@Failure		403	{object}	internal.ErrorResponse	"Missing permission"
 //	@Failure		404	{object}	internal.ErrorResponse	"Couldn't find users"
 //	@Failure		417	{object}	internal.ErrorResponse	"Issue with Data Parsing"
 //	@Failure		500	{object}	internal.ErrorResponse	"Server error"
@@ -54,17 +85,66 @@ func (s *Server) UserAll(w http.ResponseWriter, r *http.Request) {
 	// user := internal.UserFromContext(r.Context())
 
 	// Parse optional filter object
-	filter := internal.NewUserFilter(r)
+	filter, err := internal.NewUserFilter(r)
+	if err != nil {
+		internal.APIError(w, "Http::UserAll", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+	if (filter.WithTrashed || filter.OnlyTrashed) && !internal.UserFromContext(r.Context()).HasPermission(internal.PermissionUsersDelete) {
+		internal.APIError(w, "Http::UserAll", "Missing permission "+internal.PermissionUsersDelete, http.StatusForbidden, nil)
+		return
+	}
+	if v := r.URL.Query().Get("cursor"); v != "" {
+		var cursor internal.Cursor
+		if err := verifyValue(s.SigningSecret, signPurposeCursor, v, &cursor); err != nil {
+			internal.APIError(w, "Http::UserAll", "Invalid cursor", http.StatusBadRequest, err)
+			return
+		}
+		filter.Cursor = &cursor
+		filter.Offset = 0
+	}
 
 	// Fetch users from database.
-	users, err := s.UserService.FindUsers(filter)
+	users, total, err := s.UserService.FindUsers(filter)
 	if err != nil {
+		if errors.Is(err, internal.ErrInvalidCursor) {
+			internal.APIError(w, "Http::UserAll", "Invalid cursor", http.StatusBadRequest, err)
+			return
+		}
 		internal.APIError(w, "Http::UserAll", "Couldn't find users", http.StatusNotFound, err)
 		return
 	}
 
+	// Cursor of the next page, available in both pagination modes
+	var nextCursor string
+	if cursor := filter.NextCursor(users); cursor != nil {
+		if nextCursor, err = signValue(s.SigningSecret, signPurposeCursor, cursor); err != nil {
+			internal.APIError(w, "Http::UserAll", "Issue with Data Parsing", http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	// Pagination links
+	var links []pageLink
+	if filter.Cursor != nil {
+		links = cursorPageLinks(r, filter.Limit, nextCursor)
+	} else {
+		links = offsetPageLinks(r, filter.Offset, filter.Limit, total)
+	}
+	setLinkHeader(w, links)
+
+	response := UserListResponse{
+		Items:      users,
+		Total:      total,
+		Offset:     filter.Offset,
+		Limit:      filter.Limit,
+		Next:       linkURL(links, "next"),
+		Prev:       linkURL(links, "prev"),
+		NextCursor: nextCursor,
+	}
+
 	w.Header().Set("Content-type", "application/json")
-	if err := json.NewEncoder(w).Encode(users); err != nil {
+	if err := json.NewEncoder(w).Encode(response); err != nil {
 		internal.APIError(w, "Http::UserAll", "Issue with Data Parsing", http.StatusExpectationFailed, err)
 		return
 	}
@@ -76,7 +156,7 @@ func (s *Server) UserAll(w http.ResponseWriter, r *http.Request) {
 //	@Description	Fetch User by ID
 //	@Tags			users
 //	@Accept			json
-//	@Param			id	path	integer	true	"User ID" default(1)
+//	@Param			id	path	integer	true	"User ID"	default(1)
 //	@Produce		json
 //	@Success		200	{object}	[]internal.User
 //	@Failure		400	{object}	internal.ErrorResponse	"Invalid JSON body"
@@ -107,21 +187,407 @@ func (s *Server) UserFindByID(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// Represents User Create Request
+type UserCreateRequest struct {
+	Name     string `json:"name" example:"John Doe"`           // User's name
+	Email    string `json:"email" example:"johndoe@gmail.com"` // User's Email
+	Password string `json:"password" example:"Secret123"`      // User's Password, 8-72 chars with a letter & a digit
+}
+
+// Validate checks required fields of the create request.
+func (req UserCreateRequest) Validate() error {
+	if err := validateName(req.Name); err != nil {
+		return err
+	}
+	if err := validateEmail(req.Email); err != nil {
+		return err
+	}
+	return internal.ValidatePassword(req.Password, req.Email)
+}
+
+func validateName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+func validateEmail(email string) error {
+	if addr, err := mail.ParseAddress(email); err != nil || addr.Address != strings.TrimSpace(email) {
+		return errors.New("valid email is required")
+	}
+	return nil
+}
+
+// UserCreate godoc
+//
+//	@Summary		Create User
+//	@Description	Create User with hashed password. Returns created User with its Location.
+//	@Tags			users
+//	@Accept			json
+//	@Param			input	body	UserCreateRequest	true	"User Details"
+//	@Produce		json
+//	@Success		201	{object}	internal.User
+//	@Header			201	{string}	Location				"URL of created User"
+//	@Failure		400	{object}	internal.ErrorResponse	"Invalid JSON body"
+//	@Failure		401	{object}	internal.ErrorResponse	"Invalid Bearer Token"
+//	@Failure		409	{object}	internal.ErrorResponse	"Email already exists"
+//	@Failure		500	{object}	internal.ErrorResponse	"Server error"
+//	@Router			/api/v1/users [post]
+//	@Security		Bearer
 func (s *Server) UserCreate(w http.ResponseWriter, r *http.Request) {
+	// Parse create request into object
+	var req UserCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		internal.APIError(w, "Http::UserCreate", "Invalid JSON body", http.StatusBadRequest, err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		internal.APIError(w, "Http::UserCreate", err.Error(), http.StatusBadRequest, err)
+		return
+	}
+
+	// Hash the password
+	passwordHash, err := s.hashPassword(req.Password)
+	if err != nil {
+		internal.APIError(w, "Http::UserCreate", "Failed to hash password", http.StatusInternalServerError, err)
+		return
+	}
+
+	// Users created by admins don't need to verify their email
+	now := time.Now().UTC()
+	user := internal.User{
+		Name:            strings.TrimSpace(req.Name),
+		Email:           strings.TrimSpace(req.Email),
+		Password:        passwordHash,
+		EmailVerifiedAt: &now,
+	}
+	if err := s.UserService.CreateUser(&user); err != nil {
+		if errors.Is(err, internal.ErrConflict) {
+			internal.APIError(w, "Http::UserCreate", "Email already exists", http.StatusConflict, err)
+			return
+		}
+		internal.APIError(w, "Http::UserCreate", "Failed to create user", http.StatusInternalServerError, err)
+		return
+	}
+
+	// Response
+	w.Header().Set("Content-type", "application/json")
+	w.Header().Set("Location", "/api/v1/users/"+strconv.Itoa(int(user.ID)))
 	w.WriteHeader(http.StatusCreated)
-	w.Write([]byte("User created!"))
+	if err := json.NewEncoder(w).Encode(user); err != nil {
+		internal.Error("Http::UserCreate", "Issue with Data Parsing", err)
+		return
+	}
 }
 
+// Represents User Replace Request used by PUT, all fields are required
+type UserReplaceRequest struct {
+	Name  string `json:"name" example:"John Doe"`           // User's name
+	Email string `json:"email" example:"johndoe@gmail.com"` // User's Email
+}
+
+// UserUpdateByID godoc
+//
+//	@Summary		Replace User by ID
+//	@Description	Replace all editable fields of User by ID
+//	@Tags			users
+//	@Accept			json
+//	@Param			id		path	integer				true	"User ID"	default(1)
+//	@Param			input	body	UserReplaceRequest	true	"User Details"
+//	@Produce		json
+//	@Success		200	{object}	internal.User
+//	@Failure		400	{object}	internal.ErrorResponse	"Invalid JSON body"
+//	@Failure		401	{object}	internal.ErrorResponse	"Invalid Bearer Token"
+//	@Failure		403	{object}	internal.ErrorResponse	"Missing permission or User has more permissions"
+//	@Failure		404	{object}	internal.ErrorResponse	"User not found"
+//	@Failure		409	{object}	internal.ErrorResponse	"Email already exists"
+//	@Failure		500	{object}	internal.ErrorResponse	"Server error"
+//	@Router			/api/v1/users/{id} [put]
+//	@Security		Bearer
 func (s *Server) UserUpdateByID(w http.ResponseWriter, r *http.Request) {
-	w.Write([]byte("User updated!"))
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		internal.APIError(w, "Http::UserUpdateByID", "Invalid User ID", http.StatusNotFound, err)
+		return
+	}
+
+	// Parse replace request into object
+	var req UserReplaceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		internal.APIError(w, "Http::UserUpdateByID", "Invalid JSON body", http.StatusBadRequest, err)
+		return
+	}
+
+	upd := internal.UserUpdate{Name: &req.Name, Email: &req.Email}
+	s.updateUser(w, r, "Http::UserUpdateByID", id, upd)
+}
+
+// UserPatchByID godoc
+//
+//	@Summary		Patch User by ID
+//	@Description	Partially update User by ID. Supports JSON Merge Patch (RFC 7396) with
+//	@Description	`application/merge-patch+json` (or `application/json`) and JSON Patch (RFC 6902)
+//	@Description	with `application/json-patch+json`. Patchable fields: `name`, `email`.
+//	@Tags			users
+//	@Accept			json
+//	@Accept			application/merge-patch+json
+//	@Accept			application/json-patch+json
+//	@Param			id		path	integer				true	"User ID"	default(1)
+//	@Param			input	body	internal.UserUpdate	true	"Patch Document"
+//	@Produce		json
+//	@Success		200	{object}	internal.User
+//	@Failure		400	{object}	internal.ErrorResponse	"Invalid patch document"
+//	@Failure		401	{object}	internal.ErrorResponse	"Invalid Bearer Token"
+//	@Failure		403	{object}	internal.ErrorResponse	"Missing permission or User has more permissions"
+//	@Failure		404	{object}	internal.ErrorResponse	"User not found"
+//	@Failure		409	{object}	internal.ErrorResponse	"Email already exists or patch test failed"
+//	@Failure		415	{object}	internal.ErrorResponse	"Unsupported patch media type"
+//	@Failure		500	{object}	internal.ErrorResponse	"Server error"
+//	@Router			/api/v1/users/{id} [patch]
+//	@Security		Bearer
+func (s *Server) UserPatchByID(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		internal.APIError(w, "Http::UserPatchByID", "Invalid User ID", http.StatusNotFound, err)
+		return
+	}
+
+	// Pick patch format from the media type
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	var apply func(doc any, patch []byte) (any, error)
+	switch mediaType {
+	case mediaTypeJSONPatch:
+		apply = applyJSONPatch
+	case mediaTypeMergePatch, "application/json", "":
+		apply = applyMergePatch
+	default:
+		internal.APIError(w, "Http::UserPatchByID", "Unsupported patch media type", http.StatusUnsupportedMediaType, nil)
+		return
+	}
+
+	patch, err := io.ReadAll(r.Body)
+	if err != nil {
+		internal.APIError(w, "Http::UserPatchByID", "Invalid patch document", http.StatusBadRequest, err)
+		return
+	}
+
+	// Load the current state of the user to apply patch on
+	user, err := s.UserService.FindUserByID(id)
+	if err != nil {
+		internal.APIError(w, "Http::UserPatchByID", "User not found", errorStatus(err), err)
+		return
+	}
+	doc, err := apply(map[string]any{"name": user.Name, "email": user.Email}, patch)
+	if errors.Is(err, errPatchTestFailed) {
+		internal.APIError(w, "Http::UserPatchByID", "Patch test failed", http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		internal.APIError(w, "Http::UserPatchByID", "Invalid patch document", http.StatusBadRequest, err)
+		return
+	}
+
+	// Convert patched document back, rejecting unknown or removed fields
+	var req UserReplaceRequest
+	if err := decodeStrict(doc, &req); err != nil {
+		internal.APIError(w, "Http::UserPatchByID", "Invalid patch document", http.StatusBadRequest, err)
+		return
+	}
+
+	// Only update fields which were changed by the patch
+	var upd internal.UserUpdate
+	if req.Name != user.Name {
+		upd.Name = &req.Name
+	}
+	if req.Email != user.Email {
+		upd.Email = &req.Email
+	}
+	s.updateUser(w, r, "Http::UserPatchByID", id, upd)
+}
+
+// Loads the user by ID, soft deleted users included, & rejects with 403 if
+// the user has permissions the caller doesn't have, else acting on the user
+// (e.g. changing its email) could let the caller take them over. Returns
+// false if a response was written.
+func (s *Server) authorizeUserTarget(w http.ResponseWriter, r *http.Request, module string, id int) bool {
+	users, _, err := s.UserService.FindUsers(internal.UserFilter{ID: id, WithTrashed: true, Limit: 1})
+	if err == nil && len(users) == 0 {
+		err = internal.ErrNotFound
+	}
+	if err != nil {
+		internal.APIError(w, module, "User not found", errorStatus(err), err)
+		return false
+	}
+	if !internal.UserFromContext(r.Context()).HasPermissionsOf(users[0]) {
+		internal.APIError(w, module, "User has permissions you don't have", http.StatusForbidden, nil)
+		return false
+	}
+	return true
+}
+
+// Validates the update, applies it & writes updated user in response
+func (s *Server) updateUser(w http.ResponseWriter, r *http.Request, module string, id int, upd internal.UserUpdate) {
+	if !s.authorizeUserTarget(w, r, module, id) {
+		return
+	}
+
+	if v := upd.Name; v != nil {
+		*v = strings.TrimSpace(*v)
+		if err := validateName(*v); err != nil {
+			internal.APIError(w, module, err.Error(), http.StatusBadRequest, err)
+			return
+		}
+	}
+	if v := upd.Email; v != nil {
+		*v = strings.TrimSpace(*v)
+		if err := validateEmail(*v); err != nil {
+			internal.APIError(w, module, err.Error(), http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	user, err := s.UserService.UpdateUser(id, upd)
+	if err != nil {
+		switch {
+		case errors.Is(err, internal.ErrNotFound):
+			internal.APIError(w, module, "User not found", http.StatusNotFound, err)
+		case errors.Is(err, internal.ErrConflict):
+			internal.APIError(w, module, "Email already exists", http.StatusConflict, err)
+		default:
+			internal.APIError(w, module, "Failed to update user", http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	// Response
+	w.Header().Set("Content-type", "application/json")
+	if err := json.NewEncoder(w).Encode(user); err != nil {
+		internal.APIError(w, module, "Issue with Data Parsing", http.StatusExpectationFailed, err)
+		return
+	}
 }
 
+// UserDeleteByID godoc
+//
+//	@Summary		Delete User by ID
+//	@Description	Soft deletes User by ID. Permanently purges the User with `hard=true`, which needs `users:purge` permission.
+//	@Tags			users
+//	@Accept			json
+//	@Param			id		path	integer	true	"User ID"										default(1)
+//	@Param			hard	query	boolean	false	"Permanently purge User (needs users:purge)"	default(false)
+//	@Produce		json
+//	@Success		204
+//	@Failure		401	{object}	internal.ErrorResponse	"Invalid Bearer Token"
+//	@Failure		403	{object}	internal.ErrorResponse	"Missing permission or User has more permissions"
+//	@Failure		404	{object}	internal.ErrorResponse	"User not found"
+//	@Failure		500	{object}	internal.ErrorResponse	"Server error"
+//	@Router			/api/v1/users/{id} [delete]
+//	@Security		Bearer
 func (s *Server) UserDeleteByID(w http.ResponseWriter, r *http.Request) {
-	w.Write([]byte("User deleted!"))
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		internal.APIError(w, "Http::UserDeleteByID", "Invalid User ID", http.StatusNotFound, err)
+		return
+	}
+	if !s.authorizeUserTarget(w, r, "Http::UserDeleteByID", id) {
+		return
+	}
+
+	hard, _ := strconv.ParseBool(r.URL.Query().Get("hard"))
+	if hard {
+		if !internal.UserFromContext(r.Context()).HasPermission(internal.PermissionUsersPurge) {
+			internal.APIError(w, "Http::UserDeleteByID", "Missing permission "+internal.PermissionUsersPurge, http.StatusForbidden, nil)
+			return
+		}
+		err = s.UserService.PurgeUser(id)
+	} else {
+		err = s.UserService.DeleteUser(id)
+	}
+	if err != nil {
+		internal.APIError(w, "Http::UserDeleteByID", "User not found", errorStatus(err), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
 }
 
-func (s *Server) UserPatchByID(w http.ResponseWriter, r *http.Request) {
-	w.Write([]byte("User deleted!"))
+// UserRestoreByID godoc
+//
+//	@Summary		Restore User by ID
+//	@Description	Restores soft deleted User by ID
+//	@Tags			users
+//	@Accept			json
+//	@Param			id	path	integer	true	"User ID"	default(1)
+//	@Produce		json
+//	@Success		200	{object}	internal.User
+//	@Failure		401	{object}	internal.ErrorResponse	"Invalid Bearer Token"
+//	@Failure		403	{object}	internal.ErrorResponse	"Missing permission or User has more permissions"
+//	@Failure		404	{object}	internal.ErrorResponse	"Deleted User not found"
+//	@Failure		500	{object}	internal.ErrorResponse	"Server error"
+//	@Router			/api/v1/users/{id}/restore [post]
+//	@Security		Bearer
+func (s *Server) UserRestoreByID(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		internal.APIError(w, "Http::UserRestoreByID", "Invalid User ID", http.StatusNotFound, err)
+		return
+	}
+	if !s.authorizeUserTarget(w, r, "Http::UserRestoreByID", id) {
+		return
+	}
+
+	user, err := s.UserService.RestoreUser(id)
+	if err != nil {
+		internal.APIError(w, "Http::UserRestoreByID", "Deleted User not found", errorStatus(err), err)
+		return
+	}
+
+	// Response
+	w.Header().Set("Content-type", "application/json")
+	if err := json.NewEncoder(w).Encode(user); err != nil {
+		internal.APIError(w, "Http::UserRestoreByID", "Issue with Data Parsing", http.StatusExpectationFailed, err)
+		return
+	}
+}
+
+// UserUnlockByID godoc
+//
+//	@Summary		Unlock User by ID
+//	@Description	Clears Signin lockout & failed signin count of User by ID
+//	@Tags			users
+//	@Accept			json
+//	@Param			id	path	integer	true	"User ID"	default(1)
+//	@Produce		json
+//	@Success		200	{object}	internal.User
+//	@Failure		401	{object}	internal.ErrorResponse	"Invalid Bearer Token"
+//	@Failure		403	{object}	internal.ErrorResponse	"Missing permission or User has more permissions"
+//	@Failure		404	{object}	internal.ErrorResponse	"User not found"
+//	@Failure		500	{object}	internal.ErrorResponse	"Server error"
+//	@Router			/api/v1/users/{id}/unlock [post]
+//	@Security		Bearer
+func (s *Server) UserUnlockByID(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		internal.APIError(w, "Http::UserUnlockByID", "Invalid User ID", http.StatusNotFound, err)
+		return
+	}
+	if !s.authorizeUserTarget(w, r, "Http::UserUnlockByID", id) {
+		return
+	}
+
+	if err := s.UserService.UnlockUser(id); err != nil {
+		internal.APIError(w, "Http::UserUnlockByID", "User not found", errorStatus(err), err)
+		return
+	}
+
+	user, err := s.UserService.FindUserByID(id)
+	if err != nil {
+		internal.APIError(w, "Http::UserUnlockByID", "User not found", errorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, "Http::UserUnlockByID", http.StatusOK, user)
 }
 
 func (s *Server) UserOptions(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,25 @@
+package internal
+
+import "time"
+
+// Represents a link between a User & their account at an external identity
+// provider, which lets the user signin through that provider.
+type Identity struct {
+	ID        uint      `db:"id"`
+	UserID    uint      `db:"user_id"`
+	Provider  string    `db:"provider"` // Name of the configured provider
+	Subject   string    `db:"subject"`  // Stable ID of the user at the provider
+	Email     string    `db:"email"`    // Email reported by the provider when linked
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// IdentityService represents a service for managing external identities.
+type IdentityService interface {
+	// Retrieves identity by provider & subject. Returns ErrNotFound if
+	// identity does not exist.
+	FindIdentity(provider string, subject string) (*Identity, error)
+
+	// Links a new identity to the user. Sets ID & creation time on the
+	// passed identity. Returns ErrConflict if identity is already linked.
+	CreateIdentity(i *Identity) error
+}
@@ -0,0 +1,14 @@
+package internal
+
+// Represents plain text Email sent to users
+type Mail struct {
+	To      string // Recipient address
+	Subject string
+	Body    string
+}
+
+// Mailer represents a service for sending emails.
+type Mailer interface {
+	// Sends the mail, returns once it is handed over for delivery.
+	SendMail(m *Mail) error
+}
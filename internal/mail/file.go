@@ -0,0 +1,35 @@
+package mail
+
+import (
+	"go-api/internal"
+
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Ensure mailer implements interface
+var _ internal.Mailer = (*FileMailer)(nil)
+
+// FileMailer writes each mail into its own file in Dir instead of sending
+// it. Meant for local use & tests, where mails are read from the files.
+type FileMailer struct {
+	Dir string
+}
+
+// NewFileMailer returns a new instance of FileMailer.
+func NewFileMailer(dir string) *FileMailer {
+	return &FileMailer{Dir: dir}
+}
+
+// Writes the mail to a new .eml file named by the current time.
+func (m *FileMailer) SendMail(mail *internal.Mail) error {
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s.eml", time.Now().UTC().Format("20060102T150405.000000000"))
+	content := "To: " + mail.To + "\nSubject: " + mail.Subject + "\n\n" + mail.Body
+	return os.WriteFile(filepath.Join(m.Dir, name), []byte(content), 0o600)
+}
@@ -0,0 +1,23 @@
+package mail
+
+import (
+	"go-api/internal"
+)
+
+// Ensure mailer implements interface
+var _ internal.Mailer = (*LogMailer)(nil)
+
+// LogMailer writes mails to the log instead of sending them. Meant for
+// development, where links from mails can be picked from the logs.
+type LogMailer struct{}
+
+// NewLogMailer returns a new instance of LogMailer.
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+// Logs the mail.
+func (m *LogMailer) SendMail(mail *internal.Mail) error {
+	internal.Debug("Mail::LogMailer", "To: "+mail.To+"\nSubject: "+mail.Subject+"\n\n"+mail.Body)
+	return nil
+}
@@ -0,0 +1,48 @@
+package mail
+
+import (
+	"go-api/internal"
+
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+)
+
+// Ensure mailer implements interface
+var _ internal.Mailer = (*SMTPMailer)(nil)
+
+// SMTPMailer sends mails through an SMTP server, authenticating with PLAIN
+// auth if username is set.
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string // Sender address
+}
+
+// NewSMTPMailer returns a new instance of SMTPMailer.
+func NewSMTPMailer(host, port, username, password, from string) *SMTPMailer {
+	return &SMTPMailer{Host: host, Port: port, Username: username, Password: password, From: from}
+}
+
+// Sends the mail through the SMTP server.
+func (m *SMTPMailer) SendMail(mail *internal.Mail) error {
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	// Header values must not contain line breaks
+	clean := strings.NewReplacer("\r", "", "\n", "").Replace
+	msg := "From: " + clean(m.From) + "\r\n" +
+		"To: " + clean(mail.To) + "\r\n" +
+		"Subject: " + mime.QEncoding.Encode("utf-8", clean(mail.Subject)) + "\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\n" +
+		"\r\n" +
+		strings.ReplaceAll(mail.Body, "\n", "\r\n")
+
+	return smtp.SendMail(net.JoinHostPort(m.Host, m.Port), auth, m.From, []string{mail.To}, []byte(msg))
+}
@@ -0,0 +1,54 @@
+package memory
+
+import (
+	"go-api/internal"
+
+	"sync"
+	"time"
+)
+
+// Ensure store implements interface
+var _ internal.TokenRevocationStore = (*TokenRevocationStore)(nil)
+
+// TokenRevocationStore represents an in-memory implementation of
+// internal.TokenRevocationStore. Revocations are lost on restart and not
+// shared across instances, so it suits single instance setups & tests.
+type TokenRevocationStore struct {
+	mu        sync.RWMutex
+	revoked   map[string]time.Time // Token ID => Expiry
+	lastSweep time.Time
+}
+
+// NewTokenRevocationStore returns a new instance of TokenRevocationStore.
+func NewTokenRevocationStore() *TokenRevocationStore {
+	return &TokenRevocationStore{revoked: make(map[string]time.Time)}
+}
+
+// Revokes the token having given ID until expiresAt.
+func (s *TokenRevocationStore) RevokeToken(jti string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.revoked[jti] = expiresAt
+
+	// Drop expired entries, at most once a minute
+	if now := time.Now(); now.Sub(s.lastSweep) > time.Minute {
+		for id, exp := range s.revoked {
+			if now.After(exp) {
+				delete(s.revoked, id)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	return nil
+}
+
+// Checks whether the token having given ID is revoked.
+func (s *TokenRevocationStore) IsTokenRevoked(jti string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	exp, ok := s.revoked[jti]
+	return ok && time.Now().Before(exp), nil
+}
@@ -0,0 +1,39 @@
+package internal
+
+import "time"
+
+// Represents TOTP two-factor authentication of a User. It's pending until
+// activated with a valid code, after which signin requires a second factor.
+type MFA struct {
+	UserID       uint       `db:"user_id"`
+	Secret       string     `db:"secret"`         // Base32 encoded TOTP secret
+	LastUsedStep int64      `db:"last_used_step"` // Time step of the last accepted code, to prevent replays
+	EnabledAt    *time.Time `db:"enabled_at"`     // Activation time, nil while pending
+	CreatedAt    time.Time  `db:"created_at"`
+}
+
+// MFAService represents a service for managing two-factor authentication.
+type MFAService interface {
+	// Retrieves MFA of the user. Returns ErrNotFound if user hasn't enrolled.
+	FindMFA(userID int) (*MFA, error)
+
+	// Starts enrollment with a new secret, replacing a pending one.
+	// Returns ErrConflict if MFA is already enabled.
+	CreateMFA(m *MFA) error
+
+	// Activates pending MFA of the user, replacing the recovery codes with
+	// the given hashes. Returns ErrNotFound if there is no pending MFA.
+	EnableMFA(userID int, recoveryCodeHashes []string) error
+
+	// Records time step of an accepted code. Returns ErrTokenReused if a
+	// code of the same or a later step was accepted already.
+	UseMFAStep(userID int, step int64) error
+
+	// Marks unused recovery code having given hash as used. Returns
+	// ErrNotFound if there is no such code.
+	UseRecoveryCode(userID int, hash string) error
+
+	// Disables MFA of the user & removes recovery codes. Returns
+	// ErrNotFound if user hasn't enrolled.
+	DeleteMFA(userID int) error
+}
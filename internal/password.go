@@ -0,0 +1,33 @@
+package internal
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// Password policy limits. bcrypt ignores bytes beyond 72, so longer
+// passwords are rejected rather than silently truncated.
+const (
+	PasswordMinLength = 8
+	PasswordMaxLength = 72
+)
+
+// ValidatePassword checks the password against the password policy: it must
+// be 8 to 72 bytes long, contain a letter & a digit and must not be the
+// user's email.
+func ValidatePassword(password string, email string) error {
+	if len(password) < PasswordMinLength {
+		return errors.New("password must be at least 8 characters")
+	}
+	if len(password) > PasswordMaxLength {
+		return errors.New("password must be at most 72 bytes")
+	}
+	if !strings.ContainsFunc(password, unicode.IsLetter) || !strings.ContainsFunc(password, unicode.IsDigit) {
+		return errors.New("password must contain a letter and a digit")
+	}
+	if email != "" && strings.EqualFold(strings.TrimSpace(password), strings.TrimSpace(email)) {
+		return errors.New("password must not be the email")
+	}
+	return nil
+}
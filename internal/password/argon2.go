@@ -0,0 +1,127 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Default Argon2id parameters, as recommended by OWASP: 19 MiB of memory,
+// 2 iterations & 1 degree of parallelism.
+const (
+	Argon2DefaultMemory      = 19 * 1024
+	Argon2DefaultIterations  = 2
+	Argon2DefaultParallelism = 1
+
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+)
+
+// Argon2idHasher hashes passwords with Argon2id, in the PHC string format
+// `$argon2id$v=19$m=<memory>,t=<iterations>,p=<parallelism>$<salt>$<key>`.
+// Zero parameters take the defaults.
+type Argon2idHasher struct {
+	Memory      uint32 // Memory in KiB
+	Iterations  uint32
+	Parallelism uint8
+}
+
+// Parameters of an Argon2id hash
+type argon2Params struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	salt        []byte
+	key         []byte
+}
+
+func (h Argon2idHasher) params() argon2Params {
+	p := argon2Params{memory: h.Memory, iterations: h.Iterations, parallelism: h.Parallelism}
+	if p.memory == 0 {
+		p.memory = Argon2DefaultMemory
+	}
+	if p.iterations == 0 {
+		p.iterations = Argon2DefaultIterations
+	}
+	if p.parallelism == 0 {
+		p.parallelism = Argon2DefaultParallelism
+	}
+	return p
+}
+
+// Hash returns the Argon2id hash of the password.
+func (h Argon2idHasher) Hash(password string) (string, error) {
+	p := h.params()
+	p.salt = make([]byte, argon2SaltLength)
+	if _, err := rand.Read(p.salt); err != nil {
+		return "", err
+	}
+	p.key = argon2.IDKey([]byte(password), p.salt, p.iterations, p.memory, p.parallelism, argon2KeyLength)
+
+	b64 := base64.RawStdEncoding.EncodeToString
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, p.memory, p.iterations, p.parallelism, b64(p.salt), b64(p.key)), nil
+}
+
+// Handles reports whether the hash is an Argon2id hash.
+func (h Argon2idHasher) Handles(hash string) bool {
+	return strings.HasPrefix(hash, "$argon2id$")
+}
+
+// Verify checks the password against the Argon2id hash, using the
+// parameters of the hash.
+func (h Argon2idHasher) Verify(hash string, password string) error {
+	p, err := parseArgon2id(hash)
+	if err != nil {
+		return err
+	}
+	key := argon2.IDKey([]byte(password), p.salt, p.iterations, p.memory, p.parallelism, uint32(len(p.key)))
+	if subtle.ConstantTimeCompare(key, p.key) != 1 {
+		return ErrMismatch
+	}
+	return nil
+}
+
+// Outdated reports whether the Argon2id hash was made with other parameters.
+func (h Argon2idHasher) Outdated(hash string) bool {
+	p, err := parseArgon2id(hash)
+	if err != nil {
+		return true
+	}
+	want := h.params()
+	return p.memory != want.memory || p.iterations != want.iterations || p.parallelism != want.parallelism ||
+		len(p.salt) != argon2SaltLength || len(p.key) != argon2KeyLength
+}
+
+func parseArgon2id(hash string) (*argon2Params, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return nil, ErrUnknownHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, fmt.Errorf("%w: argon2 version %q", ErrUnknownHash, parts[2])
+	}
+
+	var p argon2Params
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.memory, &p.iterations, &p.parallelism); err != nil {
+		return nil, fmt.Errorf("%w: argon2 parameters %q", ErrUnknownHash, parts[3])
+	}
+	if p.iterations == 0 || p.parallelism == 0 {
+		return nil, fmt.Errorf("%w: argon2 parameters %q", ErrUnknownHash, parts[3])
+	}
+
+	var err error
+	if p.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return nil, fmt.Errorf("%w: argon2 salt", ErrUnknownHash)
+	}
+	if p.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(p.key) == 0 {
+		return nil, fmt.Errorf("%w: argon2 key", ErrUnknownHash)
+	}
+	return &p, nil
+}
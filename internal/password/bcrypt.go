@@ -0,0 +1,60 @@
+package password
+
+import (
+	"errors"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Range of bcrypt costs
+const (
+	BcryptMinCost = bcrypt.MinCost
+	BcryptMaxCost = bcrypt.MaxCost
+)
+
+// Version prefix of hashes made by this package. `$2y$` & `$2b$` hashes of
+// other implementations verify the same, but are rehashed for uniformity.
+const bcryptPrefix = "$2a$"
+
+// BcryptHasher hashes passwords with bcrypt. Only the first 72 bytes of a
+// password are used by bcrypt.
+type BcryptHasher struct {
+	Cost int // Work factor, bcrypt.DefaultCost if zero
+}
+
+func (h BcryptHasher) cost() int {
+	if h.Cost == 0 {
+		return bcrypt.DefaultCost
+	}
+	return h.Cost
+}
+
+// Hash returns the bcrypt hash of the password.
+func (h BcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.cost())
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// Handles reports whether the hash is a bcrypt hash.
+func (h BcryptHasher) Handles(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+// Verify checks the password against the bcrypt hash.
+func (h BcryptHasher) Verify(hash string, password string) error {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return ErrMismatch
+	}
+	return err
+}
+
+// Outdated reports whether the bcrypt hash has another version prefix or cost.
+func (h BcryptHasher) Outdated(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != h.cost() || !strings.HasPrefix(hash, bcryptPrefix)
+}
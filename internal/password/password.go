@@ -0,0 +1,68 @@
+// Package password hashes & verifies passwords with bcrypt or Argon2id, in
+// their standard string formats, and tells when a stored hash should be
+// upgraded to the configured algorithm & parameters.
+package password
+
+import "errors"
+
+var (
+	// Error returned when the password doesn't match the hash
+	ErrMismatch = errors.New("password doesn't match")
+
+	// Error returned when the hash isn't in a supported format
+	ErrUnknownHash = errors.New("unknown password hash format")
+)
+
+// Hasher hashes passwords with one algorithm & its parameters.
+type Hasher interface {
+	// Hash returns the hash of the password, with a random salt.
+	Hash(password string) (string, error)
+
+	// Handles reports whether the hash is in the format of this algorithm.
+	Handles(hash string) bool
+
+	// Verify checks the password against a hash of this algorithm, made
+	// with any parameters. Returns ErrMismatch if it doesn't match.
+	Verify(hash string, password string) error
+
+	// Outdated reports whether the hash of this algorithm was made with
+	// other parameters than the hasher's.
+	Outdated(hash string) bool
+}
+
+// Policy hashes new passwords with the configured Hasher, while verifying
+// hashes of every supported algorithm, so the algorithm can be changed
+// without invalidating stored hashes.
+type Policy struct {
+	hasher  Hasher
+	hashers []Hasher
+}
+
+// NewPolicy returns a Policy hashing with the given Hasher.
+func NewPolicy(hasher Hasher) *Policy {
+	return &Policy{
+		hasher:  hasher,
+		hashers: []Hasher{hasher, BcryptHasher{}, Argon2idHasher{}},
+	}
+}
+
+// Hash returns the hash of the password with the configured Hasher.
+func (p *Policy) Hash(password string) (string, error) {
+	return p.hasher.Hash(password)
+}
+
+// Verify checks the password against the hash & reports whether the hash
+// should be replaced, because it uses another algorithm or outdated
+// parameters. Returns ErrMismatch if the password doesn't match.
+func (p *Policy) Verify(hash string, password string) (rehash bool, err error) {
+	for i, h := range p.hashers {
+		if !h.Handles(hash) {
+			continue
+		}
+		if err := h.Verify(hash, password); err != nil {
+			return false, err
+		}
+		return i > 0 || h.Outdated(hash), nil
+	}
+	return false, ErrUnknownHash
+}
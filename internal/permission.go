@@ -0,0 +1,105 @@
+package internal
+
+import "strings"
+
+// Permissions follow the `module:action` vocabulary. A role can also be
+// granted `module:*` for every action of a module or `*` for everything.
+const (
+	PermissionAll = "*"
+
+	PermissionUsersRead   = "users:read"   // List & view users
+	PermissionUsersWrite  = "users:write"  // Create & update users
+	PermissionUsersDelete = "users:delete" // Soft delete, restore & list deleted users
+	PermissionUsersPurge  = "users:purge"  // Permanently delete users
+
+	PermissionUsersImpersonate = "users:impersonate" // Act as another user, having no more permissions
+
+	PermissionRolesRead  = "roles:read"  // List & view roles and their permissions
+	PermissionRolesWrite = "roles:write" // Manage roles, their permissions & assignment to users
+)
+
+// Permissions lists the whole permission vocabulary.
+var Permissions = []string{
+	PermissionAll,
+	PermissionUsersRead,
+	PermissionUsersWrite,
+	PermissionUsersDelete,
+	PermissionUsersPurge,
+	PermissionUsersImpersonate,
+	PermissionRolesRead,
+	PermissionRolesWrite,
+}
+
+// PermissionGrants checks if the granted permission covers the required one,
+// taking `*` and `module:*` wildcards into account.
+func PermissionGrants(granted string, required string) bool {
+	if granted == PermissionAll || granted == required {
+		return true
+	}
+	module, action, ok := strings.Cut(granted, ":")
+	return ok && action == "*" && strings.HasPrefix(required, module+":")
+}
+
+// HasPermission checks if any role of the user grants the permission.
+func (u *User) HasPermission(permission string) bool {
+	if u == nil {
+		return false
+	}
+	for _, p := range u.Permissions {
+		if PermissionGrants(p, permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPermissions checks if the user holds every one of the permissions.
+func (u *User) HasPermissions(permissions []string) bool {
+	for _, p := range permissions {
+		if !u.HasPermission(p) {
+			return false
+		}
+	}
+	return true
+}
+
+// HasPermissionsOf checks if the user holds every permission of other, so
+// acting on other can't gain the user any permission.
+func (u *User) HasPermissionsOf(other *User) bool {
+	return u.HasPermissions(other.Permissions)
+}
+
+// IsPermission checks if p is a known permission or a wildcard covering
+// any known permission.
+func IsPermission(p string) bool {
+	for _, known := range Permissions {
+		if p == known || (strings.HasSuffix(p, ":*") && PermissionGrants(p, known)) {
+			return true
+		}
+	}
+	return false
+}
+
+// IntersectPermissions returns the permissions granted by both lists, e.g.
+// `users:read` for `users:*` & `users:read`.
+func IntersectPermissions(a []string, b []string) []string {
+	result := []string{}
+	add := func(p string) {
+		for _, r := range result {
+			if r == p {
+				return
+			}
+		}
+		result = append(result, p)
+	}
+	for _, x := range a {
+		for _, y := range b {
+			if PermissionGrants(x, y) {
+				add(y)
+			} else if PermissionGrants(y, x) {
+				add(x)
+			}
+		}
+	}
+	return result
+}
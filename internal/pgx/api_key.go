@@ -0,0 +1,108 @@
+package pgx
+
+import (
+	"go-api/internal"
+
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Ensure service implements interface
+var _ internal.APIKeyService = (*APIKeyService)(nil)
+
+// APIKeyService represents a PostgreSQL implementation of internal.APIKeyService.
+type APIKeyService struct {
+	db *sqlx.DB
+}
+
+// NewAPIKeyService returns a new instance of APIKeyService.
+func NewAPIKeyService(db *sqlx.DB) *APIKeyService {
+	return &APIKeyService{db: db}
+}
+
+// Columns of api_keys, in the order scanned by scanAPIKey()
+const apiKeyColumns = `id, user_id, name, prefix, key_hash, scopes, expires_at, last_used_at, created_at`
+
+// Retrieves an API Key by prefix. Returns ErrNotFound if key does not exist.
+func (s *APIKeyService) FindAPIKeyByPrefix(prefix string) (*internal.APIKey, error) {
+	k, err := scanAPIKey(s.db.QueryRowx(`SELECT `+apiKeyColumns+` FROM api_keys WHERE prefix = $1`, prefix))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, internal.ErrNotFound
+		}
+		return nil, err
+	}
+	return k, nil
+}
+
+// Retrieves API Keys of the user, newest first.
+func (s *APIKeyService) FindUserAPIKeys(userID int) ([]*internal.APIKey, error) {
+	rows, err := s.db.Queryx(`SELECT `+apiKeyColumns+` FROM api_keys WHERE user_id = $1 ORDER BY id DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make([]*internal.APIKey, 0)
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+// Creates a new API Key. Sets ID & creation time on the passed key.
+func (s *APIKeyService) CreateAPIKey(k *internal.APIKey) error {
+	k.CreatedAt = time.Now().UTC()
+	if k.Scopes == nil {
+		k.Scopes = []string{}
+	}
+
+	row := s.db.QueryRowx(`
+		INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id`,
+		k.UserID, k.Name, k.Prefix, k.KeyHash, pq.Array(k.Scopes), k.ExpiresAt, k.CreatedAt)
+
+	if err := row.Scan(&k.ID); err != nil {
+		if isUniqueViolation(err) {
+			return internal.ErrConflict
+		}
+		return err
+	}
+	return nil
+}
+
+// Records use of the API Key.
+func (s *APIKeyService) TouchAPIKey(id int) error {
+	_, err := s.db.Exec(`UPDATE api_keys SET last_used_at = $1 WHERE id = $2`, time.Now().UTC(), id)
+	return err
+}
+
+// Deletes API Key of the user. Returns ErrNotFound if the user has no
+// such key.
+func (s *APIKeyService) DeleteAPIKey(userID int, id int) error {
+	result, err := s.db.Exec(`DELETE FROM api_keys WHERE user_id = $1 AND id = $2`, userID, id)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+// Scans API Key row selected with apiKeyColumns. Scopes array needs
+// pq.Array, so the row is scanned field by field.
+func scanAPIKey(row interface{ Scan(...any) error }) (*internal.APIKey, error) {
+	var k internal.APIKey
+	err := row.Scan(&k.ID, &k.UserID, &k.Name, &k.Prefix, &k.KeyHash, pq.Array(&k.Scopes), &k.ExpiresAt, &k.LastUsedAt, &k.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
@@ -0,0 +1,34 @@
+package pgx
+
+import (
+	"go-api/internal"
+
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Ensure service implements interface
+var _ internal.AuditLogService = (*AuditLogService)(nil)
+
+// AuditLogService represents a PostgreSQL implementation of internal.AuditLogService.
+type AuditLogService struct {
+	db *sqlx.DB
+}
+
+// NewAuditLogService returns a new instance of AuditLogService.
+func NewAuditLogService(db *sqlx.DB) *AuditLogService {
+	return &AuditLogService{db: db}
+}
+
+// Records a new audit log entry. Sets ID & creation time on the passed entry.
+func (s *AuditLogService) CreateAuditLog(l *internal.AuditLog) error {
+	l.CreatedAt = time.Now().UTC()
+
+	row := s.db.QueryRowx(`
+		INSERT INTO audit_logs (actor_id, subject_id, action, method, path, status, ip_address, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id`,
+		l.ActorID, l.SubjectID, l.Action, l.Method, l.Path, l.Status, l.IPAddress, l.CreatedAt)
+	return row.Scan(&l.ID)
+}
@@ -0,0 +1,59 @@
+package pgx
+
+import (
+	"go-api/internal"
+
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Ensure service implements interface
+var _ internal.IdentityService = (*IdentityService)(nil)
+
+// IdentityService represents a PostgreSQL implementation of internal.IdentityService.
+type IdentityService struct {
+	db *sqlx.DB
+}
+
+// NewIdentityService returns a new instance of IdentityService.
+func NewIdentityService(db *sqlx.DB) *IdentityService {
+	return &IdentityService{db: db}
+}
+
+// Retrieves identity by provider & subject. Returns ErrNotFound if
+// identity does not exist.
+func (s *IdentityService) FindIdentity(provider string, subject string) (*internal.Identity, error) {
+	var i internal.Identity
+
+	row := s.db.QueryRowx(`SELECT id, user_id, provider, subject, email, created_at FROM user_identities WHERE provider = $1 AND subject = $2`,
+		provider, subject)
+	if err := row.StructScan(&i); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, internal.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &i, nil
+}
+
+// Links a new identity to the user. Sets ID & creation time on the
+// passed identity. Returns ErrConflict if identity is already linked.
+func (s *IdentityService) CreateIdentity(i *internal.Identity) error {
+	i.CreatedAt = time.Now().UTC()
+
+	row := s.db.QueryRowx(`INSERT INTO user_identities (user_id, provider, subject, email, created_at)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+		i.UserID, i.Provider, i.Subject, i.Email, i.CreatedAt)
+	if err := row.Scan(&i.ID); err != nil {
+		if isUniqueViolation(err) {
+			return internal.ErrConflict
+		}
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,137 @@
+package pgx
+
+import (
+	"go-api/internal"
+
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Ensure service implements interface
+var _ internal.MFAService = (*MFAService)(nil)
+
+// MFAService represents a PostgreSQL implementation of internal.MFAService.
+type MFAService struct {
+	db *sqlx.DB
+}
+
+// NewMFAService returns a new instance of MFAService.
+func NewMFAService(db *sqlx.DB) *MFAService {
+	return &MFAService{db: db}
+}
+
+// Retrieves MFA of the user. Returns ErrNotFound if user hasn't enrolled.
+func (s *MFAService) FindMFA(userID int) (*internal.MFA, error) {
+	var m internal.MFA
+
+	err := s.db.Get(&m, `SELECT user_id, secret, last_used_step, enabled_at, created_at FROM user_mfa WHERE user_id = $1`, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, internal.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &m, nil
+}
+
+// Starts enrollment with a new secret, replacing a pending one.
+// Returns ErrConflict if MFA is already enabled.
+func (s *MFAService) CreateMFA(m *internal.MFA) error {
+	m.CreatedAt = time.Now().UTC()
+	m.EnabledAt = nil
+	m.LastUsedStep = 0
+
+	result, err := s.db.Exec(`
+		INSERT INTO user_mfa (user_id, secret, last_used_step, enabled_at, created_at)
+		VALUES ($1, $2, 0, NULL, $3)
+		ON CONFLICT (user_id) DO UPDATE SET secret = EXCLUDED.secret, created_at = EXCLUDED.created_at
+		WHERE user_mfa.enabled_at IS NULL`,
+		m.UserID, m.Secret, m.CreatedAt)
+	if err != nil {
+		return err
+	}
+	if err := expectAffected(result); err != nil {
+		return internal.ErrConflict
+	}
+	return nil
+}
+
+// Activates pending MFA of the user, replacing the recovery codes with
+// the given hashes. Returns ErrNotFound if there is no pending MFA.
+func (s *MFAService) EnableMFA(userID int, recoveryCodeHashes []string) error {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE user_mfa SET enabled_at = $1 WHERE enabled_at IS NULL AND user_id = $2`, time.Now().UTC(), userID)
+	if err != nil {
+		return err
+	}
+	if err := expectAffected(result); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM user_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	for _, hash := range recoveryCodeHashes {
+		if _, err := tx.Exec(`INSERT INTO user_recovery_codes (user_id, code_hash) VALUES ($1, $2)`, userID, hash); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Records time step of an accepted code. Returns ErrTokenReused if a
+// code of the same or a later step was accepted already.
+func (s *MFAService) UseMFAStep(userID int, step int64) error {
+	result, err := s.db.Exec(`UPDATE user_mfa SET last_used_step = $1 WHERE last_used_step < $1 AND user_id = $2`, step, userID)
+	if err != nil {
+		return err
+	}
+	if err := expectAffected(result); err != nil {
+		return internal.ErrTokenReused
+	}
+	return nil
+}
+
+// Marks unused recovery code having given hash as used. Returns
+// ErrNotFound if there is no such code.
+func (s *MFAService) UseRecoveryCode(userID int, hash string) error {
+	result, err := s.db.Exec(`UPDATE user_recovery_codes SET used_at = $1 WHERE used_at IS NULL AND user_id = $2 AND code_hash = $3`,
+		time.Now().UTC(), userID, hash)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+// Disables MFA of the user & removes recovery codes. Returns
+// ErrNotFound if user hasn't enrolled.
+func (s *MFAService) DeleteMFA(userID int) error {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM user_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	result, err := tx.Exec(`DELETE FROM user_mfa WHERE user_id = $1`, userID)
+	if err != nil {
+		return err
+	}
+	if err := expectAffected(result); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
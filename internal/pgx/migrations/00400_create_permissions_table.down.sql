@@ -0,0 +1,2 @@
+-- Drop permissions table
+DROP TABLE IF EXISTS permissions;
\ No newline at end of file
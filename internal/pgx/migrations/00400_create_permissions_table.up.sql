@@ -0,0 +1,5 @@
+-- Create permissions table, names follow `module:action` vocabulary
+CREATE TABLE IF NOT EXISTS permissions (
+  id SERIAL PRIMARY KEY,
+  name TEXT NOT NULL UNIQUE
+);
\ No newline at end of file
@@ -0,0 +1,6 @@
+-- Create role_permissions table for many-to-many relationship
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_id INT REFERENCES roles(id) ON DELETE CASCADE,
+    permission_id INT REFERENCES permissions(id) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, permission_id)
+);
\ No newline at end of file
@@ -0,0 +1,2 @@
+-- Drop email_verified_at column from users table
+ALTER TABLE users DROP COLUMN IF EXISTS email_verified_at;
\ No newline at end of file
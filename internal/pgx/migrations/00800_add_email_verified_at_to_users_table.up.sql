@@ -0,0 +1,5 @@
+-- Add email_verified_at column to users table, null until the email is verified
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP NULL DEFAULT NULL;
+
+-- Users existing before verification was introduced are treated as verified
+UPDATE users SET email_verified_at = COALESCE(created_at, NOW()) WHERE email_verified_at IS NULL;
\ No newline at end of file
@@ -0,0 +1,2 @@
+-- Drop tokens_valid_after column from users table
+ALTER TABLE users DROP COLUMN IF EXISTS tokens_valid_after;
\ No newline at end of file
@@ -0,0 +1,2 @@
+-- Add tokens_valid_after column to users table. Access Tokens issued before it are rejected
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tokens_valid_after TIMESTAMP NULL DEFAULT NULL;
\ No newline at end of file
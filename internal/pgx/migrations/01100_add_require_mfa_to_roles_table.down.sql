@@ -0,0 +1,2 @@
+-- Drop require_mfa column from roles table
+ALTER TABLE roles DROP COLUMN IF EXISTS require_mfa;
\ No newline at end of file
@@ -0,0 +1,2 @@
+-- Add require_mfa column to roles table. Users having such role must signin with two-factor authentication
+ALTER TABLE roles ADD COLUMN IF NOT EXISTS require_mfa BOOLEAN NOT NULL DEFAULT FALSE;
\ No newline at end of file
@@ -0,0 +1,2 @@
+-- Drop user_mfa table
+DROP TABLE IF EXISTS user_mfa;
\ No newline at end of file
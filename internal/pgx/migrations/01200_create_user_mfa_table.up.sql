@@ -0,0 +1,8 @@
+-- Create user_mfa table holding TOTP secret of users. MFA is active once enabled_at is set
+CREATE TABLE IF NOT EXISTS user_mfa (
+  user_id INT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+  secret TEXT NOT NULL,
+  last_used_step BIGINT NOT NULL DEFAULT 0,
+  enabled_at TIMESTAMP NULL DEFAULT NULL,
+  created_at TIMESTAMP NULL DEFAULT NULL
+);
\ No newline at end of file
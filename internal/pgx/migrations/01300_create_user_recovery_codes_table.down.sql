@@ -0,0 +1,2 @@
+-- Drop user_recovery_codes table
+DROP TABLE IF EXISTS user_recovery_codes;
\ No newline at end of file
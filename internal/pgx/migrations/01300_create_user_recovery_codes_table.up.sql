@@ -0,0 +1,8 @@
+-- Create user_recovery_codes table holding hashed single use MFA recovery codes
+CREATE TABLE IF NOT EXISTS user_recovery_codes (
+  id SERIAL PRIMARY KEY,
+  user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  code_hash TEXT NOT NULL,
+  used_at TIMESTAMP NULL DEFAULT NULL
+);
+CREATE INDEX IF NOT EXISTS user_recovery_codes_user_id_idx ON user_recovery_codes (user_id);
\ No newline at end of file
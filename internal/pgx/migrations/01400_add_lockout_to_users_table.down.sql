@@ -0,0 +1,4 @@
+-- Drop failed signin tracking & lockout columns from users table
+ALTER TABLE users
+  DROP COLUMN IF EXISTS failed_signin_attempts,
+  DROP COLUMN IF EXISTS locked_until;
\ No newline at end of file
@@ -0,0 +1,4 @@
+-- Add failed signin tracking & lockout columns to users table
+ALTER TABLE users
+  ADD COLUMN IF NOT EXISTS failed_signin_attempts INT NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP NULL DEFAULT NULL;
\ No newline at end of file
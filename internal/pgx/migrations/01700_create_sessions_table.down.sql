@@ -0,0 +1,2 @@
+-- Drop sessions table
+DROP TABLE IF EXISTS sessions;
\ No newline at end of file
@@ -0,0 +1,2 @@
+-- Drop audit_logs table
+DROP TABLE IF EXISTS audit_logs;
\ No newline at end of file
@@ -0,0 +1,14 @@
+-- Create audit_logs table, without foreign keys so entries outlive users
+CREATE TABLE IF NOT EXISTS audit_logs (
+  id BIGSERIAL PRIMARY KEY,
+  actor_id INT NOT NULL,
+  subject_id INT NOT NULL,
+  action TEXT NOT NULL,
+  method TEXT NOT NULL DEFAULT '',
+  path TEXT NOT NULL DEFAULT '',
+  status INT NOT NULL DEFAULT 0,
+  ip_address TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMP NULL DEFAULT NULL
+);
+CREATE INDEX IF NOT EXISTS audit_logs_actor_id_idx ON audit_logs (actor_id);
+CREATE INDEX IF NOT EXISTS audit_logs_subject_id_idx ON audit_logs (subject_id);
\ No newline at end of file
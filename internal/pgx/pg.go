@@ -3,13 +3,20 @@ package pgx
 import (
 	"go-api/internal"
 
+	"database/sql"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
+// Postgres error code for unique constraint violations.
+// https://www.postgresql.org/docs/current/errcodes-appendix.html
+const uniqueViolation = "23505"
+
 // Seed SQL File
 func Seed(db *sqlx.DB, sqlFile string) error {
 	internal.Debug("Pgx::Seed", sqlFile)
@@ -62,3 +69,21 @@ func FormatLimitOffset(limit, offset int) string {
 	}
 	return ""
 }
+
+// isUniqueViolation reports whether err is a Postgres unique constraint violation.
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolation
+}
+
+// expectAffected returns ErrNotFound if the statement didn't affect any rows.
+func expectAffected(result sql.Result) error {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return internal.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package pgx
+
+import (
+	"go-api/internal"
+
+	"strconv"
+	"strings"
+)
+
+// QueryBuilder collects WHERE conditions along with their positional arguments
+// ($1, $2...), so filter values are never concatenated into the SQL string.
+// Column names passed to it must come from code, never from user input.
+type QueryBuilder struct {
+	where []string
+	args  []any
+}
+
+// Arg adds a positional argument and returns its placeholder, e.g. "$3".
+// Useful for parts of the query outside WHERE like SET or LIMIT clauses.
+func (q *QueryBuilder) Arg(v any) string {
+	q.args = append(q.args, v)
+	return "$" + strconv.Itoa(len(q.args))
+}
+
+// Where adds a condition joined with AND. Each "?" in cond is replaced with
+// the placeholder of the matching arg.
+func (q *QueryBuilder) Where(cond string, args ...any) *QueryBuilder {
+	for _, v := range args {
+		cond = strings.Replace(cond, "?", q.Arg(v), 1)
+	}
+	q.where = append(q.where, cond)
+	return q
+}
+
+// Equal adds a "column = value" condition.
+func (q *QueryBuilder) Equal(column string, v any) *QueryBuilder {
+	return q.Where(column+" = ?", v)
+}
+
+// Like adds a case-sensitive "contains" condition. Wildcards in v are escaped.
+func (q *QueryBuilder) Like(column string, v string) *QueryBuilder {
+	return q.Where(column+` LIKE ? ESCAPE '\'`, "%"+EscapeLike(v)+"%")
+}
+
+// ILike adds a case-insensitive "contains" condition. Wildcards in v are escaped.
+func (q *QueryBuilder) ILike(column string, v string) *QueryBuilder {
+	return q.Where(column+` ILIKE ? ESCAPE '\'`, "%"+EscapeLike(v)+"%")
+}
+
+// After adds a keyset pagination condition selecting rows which come after
+// the row having values in the given order. For "a DESC, b ASC" it produces
+// "(a < $1 OR (a = $1 AND b > $2))".
+func (q *QueryBuilder) After(sort []internal.SortField, values []any) *QueryBuilder {
+	placeholders := make([]string, len(values))
+	for i, v := range values {
+		placeholders[i] = q.Arg(v)
+	}
+
+	or := make([]string, len(sort))
+	for i, f := range sort {
+		and := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			and = append(and, sort[j].Field+" = "+placeholders[j])
+		}
+		op := " > "
+		if f.Desc {
+			op = " < "
+		}
+		and = append(and, f.Field+op+placeholders[i])
+		or[i] = "(" + strings.Join(and, " AND ") + ")"
+	}
+	return q.Where("(" + strings.Join(or, " OR ") + ")")
+}
+
+// WhereSQL returns the WHERE clause, or an empty string if there are no conditions.
+func (q *QueryBuilder) WhereSQL() string {
+	if len(q.where) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(q.where, " AND ")
+}
+
+// Args returns the positional arguments in placeholder order.
+func (q *QueryBuilder) Args() []any {
+	return q.args
+}
+
+// EscapeLike escapes LIKE wildcards (% and _) and the escape character itself
+// so v is matched literally.
+func EscapeLike(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(v)
+}
+
+// FormatOrderBy returns the ORDER BY clause for given sort fields.
+func FormatOrderBy(sort []internal.SortField) string {
+	if len(sort) == 0 {
+		return ""
+	}
+	columns := make([]string, len(sort))
+	for i, f := range sort {
+		if f.Desc {
+			columns[i] = f.Field + " DESC"
+		} else {
+			columns[i] = f.Field + " ASC"
+		}
+	}
+	return "ORDER BY " + strings.Join(columns, ", ")
+}
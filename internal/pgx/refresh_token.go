@@ -0,0 +1,148 @@
+package pgx
+
+import (
+	"go-api/internal"
+
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Ensure service implements interface
+var _ internal.RefreshTokenService = (*RefreshTokenService)(nil)
+
+// RefreshTokenService represents a PostgreSQL implementation of internal.RefreshTokenService.
+type RefreshTokenService struct {
+	db *sqlx.DB
+}
+
+// NewRefreshTokenService returns a new instance of RefreshTokenService.
+func NewRefreshTokenService(db *sqlx.DB) *RefreshTokenService {
+	return &RefreshTokenService{db: db}
+}
+
+// Retrieves the token having given hash, whether usable or not. Returns
+// ErrNotFound if token does not exist.
+func (s *RefreshTokenService) FindRefreshToken(hash string) (*internal.RefreshToken, error) {
+	var t internal.RefreshToken
+	err := s.db.Get(&t, `SELECT id, user_id, family_id, token_hash, expires_at, used_at, revoked_at, created_at
+		FROM refresh_tokens WHERE token_hash = $1`, hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, internal.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Stores a new refresh token, starting a family if FamilyID is new.
+func (s *RefreshTokenService) CreateRefreshToken(t *internal.RefreshToken) error {
+	return createRefreshToken(s.db, t)
+}
+
+// Atomically marks the token having given hash as used and stores next
+// in the same family for the same user. Returns the used token.
+// Returns ErrNotFound if token does not exist, is expired or revoked.
+// If token was already used, it's being replayed: the whole family is
+// revoked along with its session, and ErrTokenReused is returned.
+func (s *RefreshTokenService) RotateRefreshToken(hash string, next *internal.RefreshToken) (*internal.RefreshToken, error) {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Lock the row, so concurrent rotations of the same token are serialized
+	var t internal.RefreshToken
+	row := tx.QueryRowx(`SELECT id, user_id, family_id, token_hash, expires_at, used_at, revoked_at, created_at
+		FROM refresh_tokens WHERE token_hash = $1 FOR UPDATE`, hash)
+	if err := row.StructScan(&t); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, internal.ErrNotFound
+		}
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if t.RevokedAt != nil || now.After(t.ExpiresAt) {
+		return nil, internal.ErrNotFound
+	}
+
+	// Replayed token, revoke the whole family
+	if t.UsedAt != nil {
+		if _, err := tx.Exec(`UPDATE refresh_tokens SET revoked_at = $1 WHERE family_id = $2 AND revoked_at IS NULL`, now, t.FamilyID); err != nil {
+			return nil, err
+		}
+		if _, err := tx.Exec(`UPDATE sessions SET revoked_at = $1 WHERE id = $2 AND revoked_at IS NULL`, now, t.FamilyID); err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+		return nil, internal.ErrTokenReused
+	}
+
+	if _, err := tx.Exec(`UPDATE refresh_tokens SET used_at = $1 WHERE id = $2`, now, t.ID); err != nil {
+		return nil, err
+	}
+	t.UsedAt = &now
+
+	next.UserID = t.UserID
+	next.FamilyID = t.FamilyID
+	if err := createRefreshToken(tx, next); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Revokes all tokens in the family of the token having given hash, along
+// with the session of the family. Returns ErrNotFound if token does not exist.
+func (s *RefreshTokenService) RevokeRefreshTokenFamily(hash string) error {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var familyID string
+	if err := tx.Get(&familyID, `SELECT family_id FROM refresh_tokens WHERE token_hash = $1`, hash); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return internal.ErrNotFound
+		}
+		return err
+	}
+
+	now := time.Now().UTC()
+	if _, err := tx.Exec(`UPDATE refresh_tokens SET revoked_at = $1 WHERE revoked_at IS NULL AND family_id = $2`, now, familyID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE sessions SET revoked_at = $1 WHERE revoked_at IS NULL AND id = $2`, now, familyID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Revokes all refresh tokens of the user.
+func (s *RefreshTokenService) RevokeUserRefreshTokens(userID int) error {
+	_, err := s.db.Exec(`UPDATE refresh_tokens SET revoked_at = $1 WHERE revoked_at IS NULL AND user_id = $2`,
+		time.Now().UTC(), userID)
+	return err
+}
+
+// Inserts refresh token using given db or transaction.
+func createRefreshToken(db sqlx.Queryer, t *internal.RefreshToken) error {
+	t.CreatedAt = time.Now().UTC()
+
+	row := db.QueryRowx(`INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+		t.UserID, t.FamilyID, t.TokenHash, t.ExpiresAt.UTC(), t.CreatedAt)
+	return row.Scan(&t.ID)
+}
@@ -0,0 +1,251 @@
+package pgx
+
+import (
+	"go-api/internal"
+
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Ensure service implements interface
+var _ internal.RoleService = (*RoleService)(nil)
+
+// RoleService represents a PostgreSQL implementation of internal.RoleService.
+type RoleService struct {
+	db *sqlx.DB
+}
+
+// NewRoleService returns a new instance of RoleService.
+func NewRoleService(db *sqlx.DB) *RoleService {
+	return &RoleService{db: db}
+}
+
+// Retrieves a role by ID. Returns ErrNotFound if role does not exist.
+func (s *RoleService) FindRoleByID(id int) (*internal.Role, error) {
+	var role internal.Role
+
+	if err := s.db.Get(&role, `SELECT id, name, require_mfa FROM roles WHERE id = $1`, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, internal.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &role, nil
+}
+
+// Retrieves all roles ordered by name.
+func (s *RoleService) FindRoles() ([]*internal.Role, error) {
+	roles := make([]*internal.Role, 0)
+	if err := s.db.Select(&roles, `SELECT id, name, require_mfa FROM roles ORDER BY name ASC`); err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// Creates a new role. Returns ErrConflict if the name is already taken.
+func (s *RoleService) CreateRole(role *internal.Role) error {
+	row := s.db.QueryRowx(`INSERT INTO roles (name, require_mfa) VALUES ($1, $2) RETURNING id`, role.Name, role.RequireMFA)
+
+	if err := row.Scan(&role.ID); err != nil {
+		if isUniqueViolation(err) {
+			return internal.ErrConflict
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Updates a role. Returns ErrNotFound if role does not exist and
+// ErrConflict if the new name is already taken.
+func (s *RoleService) UpdateRole(id int, upd internal.RoleUpdate) (*internal.Role, error) {
+	// Build SET clause from the fields to be updated.
+	var q QueryBuilder
+	var set []string
+	if v := upd.Name; v != nil {
+		set = append(set, "name = "+q.Arg(*v))
+	}
+	if v := upd.RequireMFA; v != nil {
+		set = append(set, "require_mfa = "+q.Arg(*v))
+	}
+
+	// Nothing to update
+	if len(set) == 0 {
+		return s.FindRoleByID(id)
+	}
+	q.Equal("id", id)
+
+	var role internal.Role
+	row := s.db.QueryRowx(`UPDATE roles SET `+strings.Join(set, ", ")+` `+q.WhereSQL()+` RETURNING id, name, require_mfa`, q.Args()...)
+
+	if err := row.StructScan(&role); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, internal.ErrNotFound
+		}
+		if isUniqueViolation(err) {
+			return nil, internal.ErrConflict
+		}
+		return nil, err
+	}
+
+	return &role, nil
+}
+
+// Deletes a role along with its assignments to users.
+// Returns ErrNotFound if role does not exist.
+func (s *RoleService) DeleteRole(id int) error {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM user_roles WHERE role_id = $1`, id); err != nil {
+		return err
+	}
+	result, err := tx.Exec(`DELETE FROM roles WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if err := expectAffected(result); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Retrieves roles assigned to the user. Returns ErrNotFound if user
+// does not exist.
+func (s *RoleService) FindUserRoles(userID int) ([]*internal.Role, error) {
+	if err := s.ensureUserExists(userID); err != nil {
+		return nil, err
+	}
+
+	roles := make([]*internal.Role, 0)
+	err := s.db.Select(&roles, `
+		SELECT r.id, r.name, r.require_mfa
+		FROM roles r
+		JOIN user_roles ur ON ur.role_id = r.id
+		WHERE ur.user_id = $1
+		ORDER BY r.name ASC`,
+		userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+// Assigns role to the user, does nothing if already assigned.
+// Returns ErrNotFound if user or role does not exist.
+func (s *RoleService) AssignRole(userID int, roleID int) error {
+	if err := s.ensureUserExists(userID); err != nil {
+		return err
+	}
+	if _, err := s.FindRoleByID(roleID); err != nil {
+		return err
+	}
+
+	_, err := s.db.Exec(`INSERT INTO user_roles (user_id, role_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`, userID, roleID)
+	return err
+}
+
+// Revokes role from the user. Returns ErrNotFound if role is not
+// assigned to the user.
+func (s *RoleService) RevokeRole(userID int, roleID int) error {
+	result, err := s.db.Exec(`DELETE FROM user_roles WHERE user_id = $1 AND role_id = $2`, userID, roleID)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+// Retrieves names of all known permissions.
+func (s *RoleService) FindPermissions() ([]string, error) {
+	permissions := make([]string, 0)
+	if err := s.db.Select(&permissions, `SELECT name FROM permissions ORDER BY name ASC`); err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+// Retrieves permissions granted to the role. Returns ErrNotFound if
+// role does not exist.
+func (s *RoleService) FindRolePermissions(roleID int) ([]string, error) {
+	if _, err := s.FindRoleByID(roleID); err != nil {
+		return nil, err
+	}
+
+	permissions := make([]string, 0)
+	err := s.db.Select(&permissions, `
+		SELECT p.name
+		FROM permissions p
+		JOIN role_permissions rp ON rp.permission_id = p.id
+		WHERE rp.role_id = $1
+		ORDER BY p.name ASC`,
+		roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	return permissions, nil
+}
+
+// Grants permission to the role, does nothing if already granted.
+// Returns ErrNotFound if role or permission does not exist.
+func (s *RoleService) GrantPermission(roleID int, permission string) error {
+	if _, err := s.FindRoleByID(roleID); err != nil {
+		return err
+	}
+
+	result, err := s.db.Exec(`
+		INSERT INTO role_permissions (role_id, permission_id)
+		SELECT $1, id FROM permissions WHERE name = $2
+		ON CONFLICT DO NOTHING`,
+		roleID, permission)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil || n > 0 {
+		return err
+	}
+
+	// Nothing inserted, either already granted or unknown permission
+	var exists bool
+	if err := s.db.Get(&exists, `SELECT EXISTS (SELECT 1 FROM permissions WHERE name = $1)`, permission); err != nil {
+		return err
+	}
+	if !exists {
+		return internal.ErrNotFound
+	}
+	return nil
+}
+
+// Revokes permission from the role. Returns ErrNotFound if permission
+// is not granted to the role.
+func (s *RoleService) RevokePermission(roleID int, permission string) error {
+	result, err := s.db.Exec(`
+		DELETE FROM role_permissions
+		WHERE role_id = $1 AND permission_id = (SELECT id FROM permissions WHERE name = $2)`,
+		roleID, permission)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+// Returns ErrNotFound if there is no active (not soft deleted) user with the ID.
+func (s *RoleService) ensureUserExists(userID int) error {
+	var exists bool
+	if err := s.db.Get(&exists, `SELECT EXISTS (SELECT 1 FROM users WHERE deleted_at IS NULL AND id = $1)`, userID); err != nil {
+		return err
+	}
+	if !exists {
+		return internal.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+INSERT INTO permissions (id, name) VALUES
+(1, '*'),
+(2, 'users:read'),
+(3, 'users:write'),
+(4, 'users:delete'),
+(5, 'users:purge'),
+(6, 'roles:read'),
+(7, 'roles:write'),
+(8, 'users:impersonate');
+
+SELECT setval('permissions_id_seq', (SELECT MAX(id) FROM permissions));
+
+INSERT INTO role_permissions (role_id, permission_id) VALUES
+(1, 1),
+(2, 2),
+(2, 3),
+(2, 4),
+(2, 6),
+(3, 2);
@@ -0,0 +1,28 @@
+INSERT INTO roles (id, name) VALUES
+(1, 'superadmin'),
+(2, 'admin'),
+(3, 'user');
+
+SELECT setval('roles_id_seq', (SELECT MAX(id) FROM roles));
+
+INSERT INTO user_roles (user_id, role_id) VALUES
+(1, 1),
+(2, 2),
+(3, 3),
+(4, 3),
+(5, 3),
+(6, 3),
+(7, 3),
+(8, 3),
+(9, 3),
+(10, 3),
+(11, 3),
+(12, 3),
+(13, 3),
+(14, 3),
+(15, 3),
+(16, 3),
+(17, 3),
+(18, 3),
+(19, 3),
+(20, 3);
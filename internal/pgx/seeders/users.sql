@@ -18,4 +18,8 @@ INSERT INTO users (id, name, email, password, created_at, updated_at, deleted_at
 (17, 'Noah Johnson', 'njohnson.tech@example.org', '$2y$10$KjZMrMDWRY6MYD2lfzxx.e6w7avkNmDRrTOFi3CZL1VN9n5/rtRPG', '2015-06-27 07:01:32', '2016-02-09 08:23:34', NULL),
 (18, 'Mia Brown', 'mbrown.pro@example.net', '$2y$10$hr.JK/piu9tjjHjwDWHJRur.Yb8xjFsDGFSav1aY7mJvv3Y98Kc/i', '2015-06-27 12:06:47', '2016-02-09 08:25:14', NULL),
 (19, 'Liam Davis', 'ldavis.work@example.com', '$2y$10$jeMMR3Q97WSIrloy9cqU0O9EfVmF3R97xUjqhm70/.zHhP6d1ACFO', '2015-06-28 03:51:35', '2016-02-13 07:56:05', NULL),
-(20, 'Charlotte Wilson', 'cwilson.dev@example.org', '$2y$10$sAPHAH8Yq8LLHKwzHY9j5OrXOkHSmB84ZNmbp8wrRm/u.71ameHPW', '2015-06-28 06:18:38', '2016-02-13 07:57:22', NULL);
\ No newline at end of file
+(20, 'Charlotte Wilson', 'cwilson.dev@example.org', '$2y$10$sAPHAH8Yq8LLHKwzHY9j5OrXOkHSmB84ZNmbp8wrRm/u.71ameHPW', '2015-06-28 06:18:38', '2016-02-13 07:57:22', NULL);
+
+UPDATE users SET email_verified_at = created_at;
+
+SELECT setval('users_id_seq', (SELECT MAX(id) FROM users));
\ No newline at end of file
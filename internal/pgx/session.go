@@ -0,0 +1,132 @@
+package pgx
+
+import (
+	"go-api/internal"
+
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Ensure service implements interface
+var _ internal.SessionService = (*SessionService)(nil)
+
+// SessionService represents a PostgreSQL implementation of internal.SessionService.
+type SessionService struct {
+	db *sqlx.DB
+}
+
+// NewSessionService returns a new instance of SessionService.
+func NewSessionService(db *sqlx.DB) *SessionService {
+	return &SessionService{db: db}
+}
+
+// Retrieves a session by ID, revoked or not. Returns ErrNotFound if
+// session does not exist.
+func (s *SessionService) FindSession(id string) (*internal.Session, error) {
+	var session internal.Session
+
+	row := s.db.QueryRowx(`SELECT id, user_id, user_agent, ip_address, created_at, last_seen_at, revoked_at FROM sessions WHERE id = $1`, id)
+	if err := row.StructScan(&session); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, internal.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// Retrieves active sessions of the user, most recently seen first. A
+// session is active until revoked or its Refresh Tokens expire.
+func (s *SessionService) FindUserSessions(userID int) ([]*internal.Session, error) {
+	rows, err := s.db.Queryx(`
+		SELECT s.id, s.user_id, s.user_agent, s.ip_address, s.created_at, s.last_seen_at, s.revoked_at
+		FROM sessions s
+		WHERE s.user_id = $1 AND s.revoked_at IS NULL AND EXISTS (
+			SELECT 1 FROM refresh_tokens rt
+			WHERE rt.family_id = s.id AND rt.used_at IS NULL AND rt.revoked_at IS NULL AND rt.expires_at > $2
+		)
+		ORDER BY s.last_seen_at DESC`,
+		userID, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := make([]*internal.Session, 0)
+	for rows.Next() {
+		var session internal.Session
+		if err := rows.StructScan(&session); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, &session)
+	}
+	return sessions, rows.Err()
+}
+
+// Creates a new session. Sets creation & last seen time on the passed
+// session.
+func (s *SessionService) CreateSession(session *internal.Session) error {
+	session.CreatedAt = time.Now().UTC()
+	session.LastSeenAt = session.CreatedAt
+
+	_, err := s.db.Exec(`INSERT INTO sessions (id, user_id, user_agent, ip_address, created_at, last_seen_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		session.ID, session.UserID, session.UserAgent, session.IPAddress, session.CreatedAt, session.LastSeenAt)
+	if isUniqueViolation(err) {
+		return internal.ErrConflict
+	}
+	return err
+}
+
+// Records activity in the session.
+func (s *SessionService) TouchSession(id string) error {
+	_, err := s.db.Exec(`UPDATE sessions SET last_seen_at = $1 WHERE id = $2`, time.Now().UTC(), id)
+	return err
+}
+
+// Revokes session of the user along with its Refresh Tokens. Returns
+// ErrNotFound if the user has no such active session.
+func (s *SessionService) RevokeSession(userID int, id string) error {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	result, err := tx.Exec(`UPDATE sessions SET revoked_at = $1 WHERE revoked_at IS NULL AND user_id = $2 AND id = $3`, now, userID, id)
+	if err != nil {
+		return err
+	}
+	if err := expectAffected(result); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE refresh_tokens SET revoked_at = $1 WHERE revoked_at IS NULL AND family_id = $2`, now, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Revokes all sessions of the user along with their Refresh Tokens.
+func (s *SessionService) RevokeUserSessions(userID int) error {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	if _, err := tx.Exec(`UPDATE sessions SET revoked_at = $1 WHERE revoked_at IS NULL AND user_id = $2`, now, userID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE refresh_tokens SET revoked_at = $1 WHERE revoked_at IS NULL AND user_id = $2`, now, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,43 @@
+package pgx
+
+import (
+	"go-api/internal"
+
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Ensure store implements interface
+var _ internal.TokenRevocationStore = (*TokenRevocationStore)(nil)
+
+// TokenRevocationStore represents a PostgreSQL implementation of
+// internal.TokenRevocationStore, shared by all instances of the server.
+type TokenRevocationStore struct {
+	db *sqlx.DB
+}
+
+// NewTokenRevocationStore returns a new instance of TokenRevocationStore.
+func NewTokenRevocationStore(db *sqlx.DB) *TokenRevocationStore {
+	return &TokenRevocationStore{db: db}
+}
+
+// Revokes the token having given ID until expiresAt. Expired entries are
+// dropped along the way.
+func (s *TokenRevocationStore) RevokeToken(jti string, expiresAt time.Time) error {
+	now := time.Now().UTC()
+	if _, err := s.db.Exec(`DELETE FROM revoked_tokens WHERE expires_at < $1`, now); err != nil {
+		return err
+	}
+	_, err := s.db.Exec(`INSERT INTO revoked_tokens (jti, expires_at, created_at) VALUES ($1, $2, $3)
+		ON CONFLICT (jti) DO NOTHING`, jti, expiresAt.UTC(), now)
+	return err
+}
+
+// Checks whether the token having given ID is revoked.
+func (s *TokenRevocationStore) IsTokenRevoked(jti string) (bool, error) {
+	var revoked bool
+	err := s.db.Get(&revoked, `SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1 AND expires_at >= $2)`,
+		jti, time.Now().UTC())
+	return revoked, err
+}
@@ -3,10 +3,13 @@ package pgx
 import (
 	"go-api/internal"
 
-	"strconv"
+	"database/sql"
+	"errors"
 	"strings"
+	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 // Ensure service implements interface
@@ -22,26 +25,40 @@ func NewUserService(db *sqlx.DB) *UserService {
 	return &UserService{db: db}
 }
 
-// Retrieves a user by ID. Returns 404 if user does not exist.
+// Retrieves a user by ID. Returns ErrNotFound if user does not exist.
 func (s *UserService) FindUserByID(id int) (*internal.User, error) {
 	var user internal.User
 
-	row := s.db.QueryRowx(`SELECT id, name, email, created_at, updated_at FROM users WHERE deleted_at IS NULL AND id = $1`, id)
+	row := s.db.QueryRowx(`SELECT id, name, email, email_verified_at, tokens_valid_after, failed_signin_attempts, locked_until, created_at, updated_at FROM users WHERE deleted_at IS NULL AND id = $1`, id)
 
 	if err := row.StructScan(&user); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, internal.ErrNotFound
+		}
+		return nil, err
+	}
+
+	if err := s.loadRolesAndPermissions(&user); err != nil {
 		return nil, err
 	}
 
 	return &user, nil
 }
 
-// Retrieves a user by Email. Returns 404 if user does not exist.
+// Retrieves a user by Email. Returns ErrNotFound if user does not exist.
 func (s *UserService) FindUserByEmail(email string) (*internal.User, error) {
 	var user internal.User
 
-	row := s.db.QueryRowx(`SELECT id, name, email, password, created_at, updated_at FROM users WHERE deleted_at IS NULL AND email = $1`, email)
+	row := s.db.QueryRowx(`SELECT id, name, email, password, email_verified_at, tokens_valid_after, failed_signin_attempts, locked_until, created_at, updated_at FROM users WHERE deleted_at IS NULL AND email = $1`, email)
 
 	if err := row.StructScan(&user); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, internal.ErrNotFound
+		}
+		return nil, err
+	}
+
+	if err := s.loadRolesAndPermissions(&user); err != nil {
 		return nil, err
 	}
 
@@ -50,20 +67,41 @@ func (s *UserService) FindUserByEmail(email string) (*internal.User, error) {
 
 // Retrieves a list of users by filter. Also returns total count of matching
 // users which may differ from returned results if filter.Limit is specified.
-func (s *UserService) FindUsers(filter internal.UserFilter) ([]*internal.User, error) {
+func (s *UserService) FindUsers(filter internal.UserFilter) ([]*internal.User, int, error) {
 	internal.Debug("PGX::FindUsers", "Filter:", filter.String())
 	// Build WHERE clause.
-	where := []string{}
+	var q QueryBuilder
 	if v := filter.ID; v != 0 {
-		where = append(where, "id = "+strconv.Itoa(v))
+		q.Equal("id", v)
 	}
 	if v := filter.Name; v != "" {
-		where = append(where, "name LIKE '%"+v+"%'")
+		q.ILike("name", v)
 	}
 	if v := filter.Email; v != "" {
-		where = append(where, "email LIKE '%"+v+"%'")
+		q.ILike("email", v)
+	}
+	if filter.OnlyTrashed {
+		q.Where("deleted_at IS NOT NULL")
+	} else if !filter.WithTrashed {
+		q.Where("deleted_at IS NULL")
+	}
+
+	// Count all matching users, regardless of limit & offset
+	var total int
+	if err := s.db.Get(&total, `SELECT COUNT(*) FROM users `+q.WhereSQL(), q.Args()...); err != nil {
+		return nil, 0, err
+	}
+
+	// Continue after the cursor position, OFFSET is not used in that case
+	orderBy := filter.OrderBy()
+	offset := filter.Offset
+	if c := filter.Cursor; c != nil {
+		if c.Sort != internal.FormatSort(filter.Sort) || len(c.Values) != len(orderBy) {
+			return nil, 0, internal.ErrInvalidCursor
+		}
+		q.After(orderBy, c.Values)
+		offset = 0
 	}
-	where = append(where, "deleted_at IS NULL")
 
 	// Query
 	query := `
@@ -71,40 +109,298 @@ func (s *UserService) FindUsers(filter internal.UserFilter) ([]*internal.User, e
 		id,
 		name,
 		email,
+		email_verified_at,
 		created_at,
-		updated_at
+		updated_at,
+		deleted_at
 		FROM users
-		WHERE ` + strings.Join(where, " AND ") + `
-		ORDER BY id ASC
-		` + FormatLimitOffset(filter.Limit, filter.Offset)
+		` + q.WhereSQL() + `
+		` + FormatOrderBy(orderBy) + `
+		` + FormatLimitOffset(filter.Limit, offset)
 
 	// Querying Data
-	rows, err := s.db.Queryx(query)
+	rows, err := s.db.Queryx(query, q.Args()...)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
+	defer rows.Close()
 
 	// Prepare Data
 	users := make([]*internal.User, 0)
 	for rows.Next() {
 		var user internal.User
 		if err := rows.StructScan(&user); err != nil {
-			return users, err
+			return users, 0, err
 		}
 		users = append(users, &user)
 	}
 	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	// Load roles & permissions of all users with single queries
+	if err := s.loadRolesAndPermissions(users...); err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+// Creates a new user. Sets ID & timestamps on the passed user.
+// Returns ErrConflict if the email is already taken.
+func (s *UserService) CreateUser(u *internal.User) error {
+	// Set timestamps to current time.
+	u.CreatedAt = time.Now().UTC()
+	u.UpdatedAt = u.CreatedAt
+
+	row := s.db.QueryRowx(`
+		INSERT INTO users (name, email, password, email_verified_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`,
+		u.Name, u.Email, u.Password, u.EmailVerifiedAt, u.CreatedAt, u.UpdatedAt)
+
+	if err := row.Scan(&u.ID); err != nil {
+		if isUniqueViolation(err) {
+			return internal.ErrConflict
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Updates a user object. Only non-nil fields of upd are changed, and a
+// changed email is unverified again. Returns ErrNotFound if user does
+// not exist or is soft deleted.
+func (s *UserService) UpdateUser(id int, upd internal.UserUpdate) (*internal.User, error) {
+	// Build SET clause from the fields to be updated.
+	var q QueryBuilder
+	set := []string{"updated_at = " + q.Arg(time.Now().UTC())}
+	if v := upd.Name; v != nil {
+		set = append(set, "name = "+q.Arg(*v))
+	}
+	if v := upd.Email; v != nil {
+		// A new email is unverified until proven again
+		email := q.Arg(*v)
+		set = append(set, "email = "+email, "email_verified_at = CASE WHEN email = "+email+" THEN email_verified_at END")
+	}
+	q.Where("deleted_at IS NULL").Equal("id", id)
+
+	var user internal.User
+	row := s.db.QueryRowx(`
+		UPDATE users SET `+strings.Join(set, ", ")+`
+		`+q.WhereSQL()+`
+		RETURNING id, name, email, email_verified_at, created_at, updated_at`,
+		q.Args()...)
+
+	if err := row.StructScan(&user); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, internal.ErrNotFound
+		}
+		if isUniqueViolation(err) {
+			return nil, internal.ErrConflict
+		}
+		return nil, err
+	}
+
+	if err := s.loadRolesAndPermissions(&user); err != nil {
 		return nil, err
 	}
 
-	return users, nil
+	return &user, nil
+}
+
+// Marks email of the user as verified, keeping the time of an earlier
+// verification. Returns ErrNotFound if user does not exist.
+func (s *UserService) VerifyUserEmail(id int) error {
+	result, err := s.db.Exec(`UPDATE users SET email_verified_at = COALESCE(email_verified_at, $1) WHERE deleted_at IS NULL AND id = $2`,
+		time.Now().UTC(), id)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+// Replaces password hash of the user & invalidates Access Tokens issued
+// before. Returns ErrNotFound if user does not exist.
+func (s *UserService) UpdateUserPassword(id int, passwordHash string) error {
+	now := time.Now().UTC()
+	result, err := s.db.Exec(`UPDATE users SET password = $1, tokens_valid_after = $2, updated_at = $2 WHERE deleted_at IS NULL AND id = $3`,
+		passwordHash, now, id)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
 }
 
-// Creates a new user.
-// func (s *UserService) CreateUser(u internal.User) error
+// Replaces password hash of the user with an upgraded hash of the same
+// password, keeping issued Access Tokens valid. Returns ErrNotFound if
+// user does not exist or its hash isn't oldHash anymore.
+func (s *UserService) RehashUserPassword(id int, oldHash string, newHash string) error {
+	result, err := s.db.Exec(`UPDATE users SET password = $1 WHERE deleted_at IS NULL AND id = $2 AND password = $3`,
+		newHash, id, oldHash)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
 
-// Updates a user object. Returns 404 if user does not exist.
-// func (s *UserService) UpdateUser(id int, upd internal.UserUpdate) (internal.User, error)
+// Counts a failed signin of the user & returns the number of consecutive
+// failures. Returns ErrNotFound if user does not exist.
+func (s *UserService) RecordFailedSignin(id int) (int, error) {
+	var attempts int
+	err := s.db.Get(&attempts, `UPDATE users SET failed_signin_attempts = failed_signin_attempts + 1 WHERE deleted_at IS NULL AND id = $1 RETURNING failed_signin_attempts`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, internal.ErrNotFound
+	}
+	return attempts, err
+}
 
-// Soft Deletes User if found. Returns 404 if user does not exist.
-// func (s *UserService) DeleteUser(id int) error
+// Locks signin of the user until given time. Returns ErrNotFound if
+// user does not exist.
+func (s *UserService) LockUser(id int, until time.Time) error {
+	result, err := s.db.Exec(`UPDATE users SET locked_until = $1 WHERE deleted_at IS NULL AND id = $2`, until.UTC(), id)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+// Clears the lock & failed signin count of the user. Returns
+// ErrNotFound if user does not exist.
+func (s *UserService) UnlockUser(id int) error {
+	result, err := s.db.Exec(`UPDATE users SET failed_signin_attempts = 0, locked_until = NULL WHERE deleted_at IS NULL AND id = $1`, id)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+// Soft Deletes User if found. Returns ErrNotFound if user does not exist
+// or is already deleted.
+func (s *UserService) DeleteUser(id int) error {
+	result, err := s.db.Exec(`UPDATE users SET deleted_at = $1 WHERE deleted_at IS NULL AND id = $2`, time.Now().UTC(), id)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+// Restores a soft deleted User. Returns ErrNotFound if there is no
+// deleted user with the given ID.
+func (s *UserService) RestoreUser(id int) (*internal.User, error) {
+	var user internal.User
+
+	row := s.db.QueryRowx(`
+		UPDATE users SET deleted_at = NULL, updated_at = $1
+		WHERE deleted_at IS NOT NULL AND id = $2
+		RETURNING id, name, email, email_verified_at, created_at, updated_at`,
+		time.Now().UTC(), id)
+
+	if err := row.StructScan(&user); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, internal.ErrNotFound
+		}
+		return nil, err
+	}
+
+	if err := s.loadRolesAndPermissions(&user); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// Permanently removes User & its role assignments, whether soft deleted
+// or not. Returns ErrNotFound if user does not exist.
+func (s *UserService) PurgeUser(id int) error {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM user_roles WHERE user_id = $1`, id); err != nil {
+		return err
+	}
+	result, err := tx.Exec(`DELETE FROM users WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if err := expectAffected(result); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Loads role names & permissions of the given users, with a single query each.
+func (s *UserService) loadRolesAndPermissions(users ...*internal.User) error {
+	if len(users) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(users))
+	byID := make(map[uint]*internal.User, len(users))
+	for i, u := range users {
+		ids[i] = int64(u.ID)
+		byID[u.ID] = u
+		u.Roles = []string{}
+		u.Permissions = []string{}
+		u.MFARequired = false
+	}
+
+	rows, err := s.db.Queryx(`
+		SELECT ur.user_id, r.name, r.require_mfa
+		FROM user_roles ur
+		JOIN roles r ON r.id = ur.role_id
+		WHERE ur.user_id = ANY($1)
+		ORDER BY r.name ASC`,
+		pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID uint
+		var role string
+		var requireMFA bool
+		if err := rows.Scan(&userID, &role, &requireMFA); err != nil {
+			return err
+		}
+		if u := byID[userID]; u != nil {
+			u.Roles = append(u.Roles, role)
+			u.MFARequired = u.MFARequired || requireMFA
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	// Permissions granted by any of the user roles
+	rows, err = s.db.Queryx(`
+		SELECT DISTINCT ur.user_id, p.name
+		FROM user_roles ur
+		JOIN role_permissions rp ON rp.role_id = ur.role_id
+		JOIN permissions p ON p.id = rp.permission_id
+		WHERE ur.user_id = ANY($1)
+		ORDER BY p.name ASC`,
+		pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID uint
+		var permission string
+		if err := rows.Scan(&userID, &permission); err != nil {
+			return err
+		}
+		if u := byID[userID]; u != nil {
+			u.Permissions = append(u.Permissions, permission)
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,66 @@
+package pgx
+
+import (
+	"go-api/internal"
+
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Ensure service implements interface
+var _ internal.UserTokenService = (*UserTokenService)(nil)
+
+// UserTokenService represents a PostgreSQL implementation of internal.UserTokenService.
+type UserTokenService struct {
+	db *sqlx.DB
+}
+
+// NewUserTokenService returns a new instance of UserTokenService.
+func NewUserTokenService(db *sqlx.DB) *UserTokenService {
+	return &UserTokenService{db: db}
+}
+
+// Stores a new user token.
+func (s *UserTokenService) CreateUserToken(t *internal.UserToken) error {
+	t.CreatedAt = time.Now().UTC()
+
+	row := s.db.QueryRowx(`INSERT INTO user_tokens (user_id, purpose, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+		t.UserID, t.Purpose, t.TokenHash, t.ExpiresAt.UTC(), t.CreatedAt)
+	return row.Scan(&t.ID)
+}
+
+// Atomically marks the token having given purpose & hash as used and
+// returns it. Returns ErrNotFound if token does not exist, is expired
+// or already used.
+func (s *UserTokenService) ConsumeUserToken(purpose string, hash string) (*internal.UserToken, error) {
+	var t internal.UserToken
+
+	now := time.Now().UTC()
+	row := s.db.QueryRowx(`
+		UPDATE user_tokens SET used_at = $1
+		WHERE used_at IS NULL AND expires_at > $1 AND purpose = $2 AND token_hash = $3
+		RETURNING id, user_id, purpose, token_hash, expires_at, used_at, created_at`,
+		now, purpose, hash)
+
+	if err := row.StructScan(&t); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, internal.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+// Expires all unused tokens of the user, e.g. once the password is set,
+// so earlier reset & signin links can't be used anymore.
+func (s *UserTokenService) ExpireUserTokens(userID int) error {
+	now := time.Now().UTC()
+	_, err := s.db.Exec(`UPDATE user_tokens SET expires_at = $1 WHERE used_at IS NULL AND expires_at > $1 AND user_id = $2`,
+		now, userID)
+	return err
+}
@@ -0,0 +1,42 @@
+package internal
+
+import "time"
+
+// Represents an opaque Refresh Token issued along with the Access Token.
+// Only the SHA-256 hash of the token is stored. Each refresh rotates the
+// token: the presented one is marked used and a new one is issued in the
+// same family.
+type RefreshToken struct {
+	ID        uint       `db:"id"`
+	UserID    uint       `db:"user_id"`
+	FamilyID  string     `db:"family_id"`  // Shared by all tokens rotated from the same signin
+	TokenHash string     `db:"token_hash"` // Hex encoded SHA-256 of the token
+	ExpiresAt time.Time  `db:"expires_at"`
+	UsedAt    *time.Time `db:"used_at"`
+	RevokedAt *time.Time `db:"revoked_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
+
+// RefreshTokenService represents a service for managing refresh tokens.
+type RefreshTokenService interface {
+	// Retrieves the token having given hash, whether usable or not. Returns
+	// ErrNotFound if token does not exist.
+	FindRefreshToken(hash string) (*RefreshToken, error)
+
+	// Stores a new refresh token, starting a family if FamilyID is new.
+	CreateRefreshToken(t *RefreshToken) error
+
+	// Atomically marks the token having given hash as used and stores next
+	// in the same family for the same user. Returns the used token.
+	// Returns ErrNotFound if token does not exist, is expired or revoked.
+	// If token was already used, it's being replayed: the whole family is
+	// revoked along with its session, and ErrTokenReused is returned.
+	RotateRefreshToken(hash string, next *RefreshToken) (*RefreshToken, error)
+
+	// Revokes all tokens in the family of the token having given hash, along
+	// with the session of the family. Returns ErrNotFound if token does not exist.
+	RevokeRefreshTokenFamily(hash string) error
+
+	// Revokes all refresh tokens of the user.
+	RevokeUserRefreshTokens(userID int) error
+}
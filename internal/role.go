@@ -0,0 +1,62 @@
+package internal
+
+// Represents Role which can be assigned to Users
+type Role struct {
+	ID   uint   `db:"id" json:"id" example:"1"`              // Role's ID
+	Name string `db:"name" json:"name" example:"superadmin"` // Role's unique name
+
+	RequireMFA bool `db:"require_mfa" json:"requireMfa" example:"false"` // Users having the role must signin with two-factor authentication
+}
+
+// RoleService represents a service for managing roles & their assignment to users.
+type RoleService interface {
+	// Retrieves a role by ID. Returns ErrNotFound if role does not exist.
+	FindRoleByID(id int) (*Role, error)
+
+	// Retrieves all roles ordered by name.
+	FindRoles() ([]*Role, error)
+
+	// Creates a new role. Returns ErrConflict if the name is already taken.
+	CreateRole(role *Role) error
+
+	// Updates a role. Returns ErrNotFound if role does not exist and
+	// ErrConflict if the new name is already taken.
+	UpdateRole(id int, upd RoleUpdate) (*Role, error)
+
+	// Deletes a role along with its assignments to users.
+	// Returns ErrNotFound if role does not exist.
+	DeleteRole(id int) error
+
+	// Retrieves roles assigned to the user. Returns ErrNotFound if user
+	// does not exist.
+	FindUserRoles(userID int) ([]*Role, error)
+
+	// Assigns role to the user, does nothing if already assigned.
+	// Returns ErrNotFound if user or role does not exist.
+	AssignRole(userID int, roleID int) error
+
+	// Revokes role from the user. Returns ErrNotFound if role is not
+	// assigned to the user.
+	RevokeRole(userID int, roleID int) error
+
+	// Retrieves names of all known permissions.
+	FindPermissions() ([]string, error)
+
+	// Retrieves permissions granted to the role. Returns ErrNotFound if
+	// role does not exist.
+	FindRolePermissions(roleID int) ([]string, error)
+
+	// Grants permission to the role, does nothing if already granted.
+	// Returns ErrNotFound if role or permission does not exist.
+	GrantPermission(roleID int, permission string) error
+
+	// Revokes permission from the role. Returns ErrNotFound if permission
+	// is not granted to the role.
+	RevokePermission(roleID int, permission string) error
+}
+
+// RoleUpdate represents a set of fields to be updated via UpdateRole().
+type RoleUpdate struct {
+	Name       *string `json:"name" example:"manager"`     // Role's name
+	RequireMFA *bool   `json:"requireMfa" example:"false"` // Require two-factor authentication
+}
@@ -0,0 +1,42 @@
+package internal
+
+import "time"
+
+// Represents a login of a User on a device, from signin until signout or
+// revocation. The ID is the family ID of its Refresh Tokens and the `sid`
+// claim of its Access Tokens.
+type Session struct {
+	ID         string     `db:"id" json:"id" example:"nH-VMdHEiBLMDXXI0QibKLS7hGECDV5MEYs_k5369aI"` // Session's ID
+	UserID     uint       `db:"user_id" json:"-"`                                                   // Owner User's ID
+	UserAgent  string     `db:"user_agent" json:"userAgent" example:"Mozilla/5.0"`                  // User Agent of the client at signin
+	IPAddress  string     `db:"ip_address" json:"ipAddress" example:"127.0.0.1"`                    // IP Address of the client at signin
+	CreatedAt  time.Time  `db:"created_at" json:"createdAt" example:"2024-05-03T15:34:26.460Z"`     // Signin Time
+	LastSeenAt time.Time  `db:"last_seen_at" json:"lastSeenAt" example:"2024-05-03T15:34:26.460Z"`  // Last Request or Refresh Time
+	RevokedAt  *time.Time `db:"revoked_at" json:"-"`                                                // Signout or Revocation Time
+	Current    bool       `db:"-" json:"current" example:"true"`                                    // Whether the request is made from this session
+}
+
+// SessionService represents a service for managing user sessions.
+type SessionService interface {
+	// Retrieves a session by ID, revoked or not. Returns ErrNotFound if
+	// session does not exist.
+	FindSession(id string) (*Session, error)
+
+	// Retrieves active sessions of the user, most recently seen first. A
+	// session is active until revoked or its Refresh Tokens expire.
+	FindUserSessions(userID int) ([]*Session, error)
+
+	// Creates a new session. Sets creation & last seen time on the passed
+	// session.
+	CreateSession(s *Session) error
+
+	// Records activity in the session.
+	TouchSession(id string) error
+
+	// Revokes session of the user along with its Refresh Tokens. Returns
+	// ErrNotFound if the user has no such active session.
+	RevokeSession(userID int, id string) error
+
+	// Revokes all sessions of the user along with their Refresh Tokens.
+	RevokeUserSessions(userID int) error
+}
@@ -0,0 +1,48 @@
+package internal
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SortField represents a single ORDER BY column of a listing.
+type SortField struct {
+	Field string `json:"field" example:"created_at"` // Column name
+	Desc  bool   `json:"desc" example:"true"`        // Descending order
+}
+
+// ParseSort parses a sort query value like "-created_at,name" into sort
+// fields. A "-" prefix sorts descending. Fields must be in allowed list.
+func ParseSort(v string, allowed []string) ([]SortField, error) {
+	var sort []SortField
+	for _, part := range strings.Split(v, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		field := SortField{Field: strings.TrimPrefix(part, "-"), Desc: strings.HasPrefix(part, "-")}
+		if !slices.Contains(allowed, field.Field) {
+			return nil, fmt.Errorf("cannot sort by %q, allowed fields: %s", field.Field, strings.Join(allowed, ", "))
+		}
+		if slices.ContainsFunc(sort, func(f SortField) bool { return f.Field == field.Field }) {
+			return nil, fmt.Errorf("duplicate sort field %q", field.Field)
+		}
+		sort = append(sort, field)
+	}
+	return sort, nil
+}
+
+// FormatSort formats sort fields back into the "-created_at,name" form.
+func FormatSort(sort []SortField) string {
+	parts := make([]string, len(sort))
+	for i, f := range sort {
+		if f.Desc {
+			parts[i] = "-" + f.Field
+		} else {
+			parts[i] = f.Field
+		}
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,13 @@
+package internal
+
+import "time"
+
+// TokenRevocationStore keeps IDs (`jti` claim) of revoked Access Tokens, e.g.
+// on signout, until the tokens expire on their own.
+type TokenRevocationStore interface {
+	// Revokes the token having given ID. Entry may be dropped after expiresAt.
+	RevokeToken(jti string, expiresAt time.Time) error
+
+	// Checks whether the token having given ID is revoked.
+	IsTokenRevoked(jti string) (bool, error)
+}
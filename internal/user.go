@@ -3,6 +3,7 @@ package internal
 import (
 	"fmt"
 	"net/http"
+	"slices"
 	"strconv"
 	"time"
 )
@@ -12,35 +13,119 @@ type User struct {
 	ID       uint     `db:"id" json:"id" example:"123"`                     // User's ID
 	Name     string   `db:"name" json:"name" example:"John Doe"`            // User's name
 	Email    string   `db:"email" json:"email" example:"johndoe@gmail.com"` // User's Email
-	Password string   `db:"password" json:"password" example:"Masked"`      // User's Password
+	Password string   `db:"password" json:"-"`                              // User's Password Hash, never serialized
 	Roles    []string `db:"roles" json:"roles" example:"['superadmin']"`    // User Roles
 
+	Permissions []string `db:"permissions" json:"permissions" example:"['users:read']"` // Permissions granted by User Roles
+	MFARequired bool     `db:"-" json:"mfaRequired" example:"false"`                    // Whether any of User Roles requires two-factor authentication
+
+	// Access Tokens issued before this time are rejected, e.g. after password reset
+	TokensValidAfter *time.Time `db:"tokens_valid_after" json:"-"`
+
+	// Brute-force protection of Signin
+	FailedSigninAttempts int        `db:"failed_signin_attempts" json:"-"`                                              // Consecutive failed signins
+	LockedUntil          *time.Time `db:"locked_until" json:"lockedUntil,omitempty" example:"2024-05-03T15:34:26.460Z"` // Signin is locked until this time
+
 	// Timestamps
-	CreatedAt time.Time `db:"created_at" json:"createdAt" example:"2024-05-03T15:34:26.460Z"` // User's Creation Time
-	UpdatedAt time.Time `db:"updated_at" json:"updatedAt" example:"2024-05-03T15:34:26.460Z"` // User's Updation Time
-	DeletedAt time.Time `db:"deleted_at" json:"deletedAt" example:"2024-05-03T15:34:26.460Z"` // Deletion time if User is Deleted
+	EmailVerifiedAt *time.Time `db:"email_verified_at" json:"emailVerifiedAt" example:"2024-05-03T15:34:26.460Z"` // Email Verification Time, null if unverified
+	CreatedAt       time.Time  `db:"created_at" json:"createdAt" example:"2024-05-03T15:34:26.460Z"`              // User's Creation Time
+	UpdatedAt       time.Time  `db:"updated_at" json:"updatedAt" example:"2024-05-03T15:34:26.460Z"`              // User's Updation Time
+	DeletedAt       *time.Time `db:"deleted_at" json:"deletedAt,omitempty" example:"2024-05-03T15:34:26.460Z"`    // Deletion time if User is Deleted
+}
+
+// HasRole checks if user has been assigned the given role.
+func (u *User) HasRole(role string) bool {
+	for _, r := range u.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
 }
 
 // UserService represents a service for managing users.
 type UserService interface {
-	// Retrieves a user by ID. Returns 404 if user does not exist.
+	// Retrieves a user by ID. Returns ErrNotFound if user does not exist.
 	FindUserByID(id int) (*User, error)
 
-	// Retrieves a user by Email. Returns 404 if user does not exist.
+	// Retrieves a user by Email. Returns ErrNotFound if user does not exist.
 	FindUserByEmail(email string) (*User, error)
 
 	// Retrieves a list of users by filter. Also returns total count of matching
 	// users which may differ from returned results if filter.Limit is specified.
-	FindUsers(filter UserFilter) ([]*User, error)
+	FindUsers(filter UserFilter) ([]*User, int, error)
+
+	// Creates a new user. Sets ID & timestamps on the passed user.
+	// Returns ErrConflict if the email is already taken.
+	CreateUser(u *User) error
+
+	// Updates a user object. Only non-nil fields of upd are changed, and a
+	// changed email is unverified again. Returns ErrNotFound if user does
+	// not exist or is soft deleted.
+	UpdateUser(id int, upd UserUpdate) (*User, error)
+
+	// Marks email of the user as verified, keeping the time of an earlier
+	// verification. Returns ErrNotFound if user does not exist.
+	VerifyUserEmail(id int) error
+
+	// Replaces password hash of the user & invalidates Access Tokens issued
+	// before. Returns ErrNotFound if user does not exist.
+	UpdateUserPassword(id int, passwordHash string) error
+
+	// Replaces password hash of the user with an upgraded hash of the same
+	// password, keeping issued Access Tokens valid. Returns ErrNotFound if
+	// user does not exist or its hash isn't oldHash anymore.
+	RehashUserPassword(id int, oldHash string, newHash string) error
+
+	// Counts a failed signin of the user & returns the number of consecutive
+	// failures. Returns ErrNotFound if user does not exist.
+	RecordFailedSignin(id int) (int, error)
+
+	// Locks signin of the user until given time. Returns ErrNotFound if
+	// user does not exist.
+	LockUser(id int, until time.Time) error
+
+	// Clears the lock & failed signin count of the user. Returns
+	// ErrNotFound if user does not exist.
+	UnlockUser(id int) error
+
+	// Soft Deletes User if found. Returns ErrNotFound if user does not exist
+	// or is already deleted.
+	DeleteUser(id int) error
+
+	// Restores a soft deleted User. Returns ErrNotFound if there is no
+	// deleted user with the given ID.
+	RestoreUser(id int) (*User, error)
+
+	// Permanently removes User & its role assignments, whether soft deleted
+	// or not. Returns ErrNotFound if user does not exist.
+	PurgeUser(id int) error
+}
 
-	// Creates a new user.
-	// CreateUser(u *User) error
+// Page size limits for listing APIs
+const (
+	DefaultPageLimit = 20  // Used when limit is not specified
+	MaxPageLimit     = 100 // Larger limits are capped to this
+)
 
-	// Updates a user object. Returns 404 if user does not exist.
-	// UpdateUser(id int, upd UserUpdate) (*User, error)
+// Columns which Users can be sorted by
+var UserSortFields = []string{"id", "name", "email", "created_at", "updated_at"}
 
-	// Soft Deletes User if found. Returns 404 if user does not exist.
-	// DeleteUser(id int) error
+// SortValue returns value of the given sortable column.
+func (u *User) SortValue(field string) any {
+	switch field {
+	case "id":
+		return u.ID
+	case "name":
+		return u.Name
+	case "email":
+		return u.Email
+	case "created_at":
+		return u.CreatedAt
+	case "updated_at":
+		return u.UpdatedAt
+	}
+	return nil
 }
 
 // UserFilter represents a filter passed to FindUsers().
@@ -50,19 +135,34 @@ type UserFilter struct {
 	Name  string `json:"name" example:"John Doe"`           // User's name
 	Email string `json:"email" example:"johndoe@gmail.com"` // User's Email
 
+	// Soft deleted users are excluded unless one of these is set.
+	WithTrashed bool `json:"withTrashed" example:"false"` // Include soft deleted Users
+	OnlyTrashed bool `json:"onlyTrashed" example:"false"` // Only soft deleted Users
+
+	// Ordering of results, by ID if empty.
+	Sort []SortField `json:"sort"` // Sort Fields
+
 	// Restrict to subset of results.
-	Offset int `json:"offset" example:"0"` // Pagination Offset
-	Limit  int `json:"limit" example:"20"` // Number of Records to be fetched
+	Offset int     `json:"offset" example:"0"` // Pagination Offset, ignored if Cursor is set
+	Limit  int     `json:"limit" example:"20"` // Number of Records to be fetched
+	Cursor *Cursor `json:"-"`                  // Keyset position to continue after
 }
 
 // Stringer Interface: Override Default String Method of Struct for Rectangle
 func (f UserFilter) String() string {
-	return fmt.Sprintf("{ ID: %d, Name: %s, Email: %s, Offset: %d, Limit: %d }", f.ID, f.Name, f.Email, f.Offset, f.Limit)
+	return fmt.Sprintf("{ ID: %d, Name: %s, Email: %s, WithTrashed: %t, OnlyTrashed: %t, Sort: %s, Offset: %d, Limit: %d }", f.ID, f.Name, f.Email, f.WithTrashed, f.OnlyTrashed, FormatSort(f.Sort), f.Offset, f.Limit)
 }
 
-func NewUserFilter(r *http.Request) UserFilter {
+// NewUserFilter parses the filter from request query. Returns error if the
+// sort is invalid.
+func NewUserFilter(r *http.Request) (UserFilter, error) {
 	q := r.URL.Query()
 
+	sort, err := ParseSort(q.Get("sort"), UserSortFields)
+	if err != nil {
+		return UserFilter{}, err
+	}
+
 	id, err := strconv.Atoi(q.Get("id"))
 	if err != nil {
 		id = 0
@@ -71,18 +171,54 @@ func NewUserFilter(r *http.Request) UserFilter {
 	if err != nil {
 		offset = 0
 	}
+	if offset < 0 {
+		offset = 0
+	}
 	limit, err := strconv.Atoi(q.Get("limit"))
-	if err != nil {
-		limit = 0
+	if err != nil || limit <= 0 {
+		limit = DefaultPageLimit
+	}
+	if limit > MaxPageLimit {
+		limit = MaxPageLimit
 	}
+	withTrashed, _ := strconv.ParseBool(q.Get("withTrashed"))
+	onlyTrashed, _ := strconv.ParseBool(q.Get("onlyTrashed"))
+
 	// Parse optional filter object
 	return UserFilter{
-		ID:     id,
-		Name:   q.Get("name"),
-		Email:  q.Get("email"),
-		Offset: offset,
-		Limit:  limit,
+		ID:          id,
+		Name:        q.Get("name"),
+		Email:       q.Get("email"),
+		WithTrashed: withTrashed,
+		OnlyTrashed: onlyTrashed,
+		Sort:        sort,
+		Offset:      offset,
+		Limit:       limit,
+	}, nil
+}
+
+// OrderBy returns sort fields to order the listing by. ID is appended as a
+// tie breaker, so the order is stable for keyset pagination.
+func (f UserFilter) OrderBy() []SortField {
+	sort := slices.Clone(f.Sort)
+	if !slices.ContainsFunc(sort, func(s SortField) bool { return s.Field == "id" }) {
+		sort = append(sort, SortField{Field: "id"})
+	}
+	return sort
+}
+
+// NextCursor returns the cursor of the page after users, which were fetched
+// with this filter. Returns nil if users is the last page.
+func (f UserFilter) NextCursor(users []*User) *Cursor {
+	if len(users) == 0 || len(users) < f.Limit {
+		return nil
+	}
+	last := users[len(users)-1]
+	cursor := &Cursor{Sort: FormatSort(f.Sort)}
+	for _, s := range f.OrderBy() {
+		cursor.Values = append(cursor.Values, last.SortValue(s.Field))
 	}
+	return cursor
 }
 
 // UserUpdate represents a set of fields to be updated via UpdateUser().
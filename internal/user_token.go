@@ -0,0 +1,37 @@
+package internal
+
+import "time"
+
+// Purposes of user tokens. A token is only accepted for its own purpose.
+const (
+	UserTokenEmailVerification = "email_verification"
+	UserTokenPasswordReset     = "password_reset"
+	UserTokenMagicLink         = "magic_link"
+)
+
+// Represents a single use, expiring token sent to the user, e.g. in an
+// email verification link. Only the SHA-256 hash of the token is stored.
+type UserToken struct {
+	ID        uint       `db:"id"`
+	UserID    uint       `db:"user_id"`
+	Purpose   string     `db:"purpose"`
+	TokenHash string     `db:"token_hash"` // Hex encoded SHA-256 of the token
+	ExpiresAt time.Time  `db:"expires_at"`
+	UsedAt    *time.Time `db:"used_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
+
+// UserTokenService represents a service for managing user tokens.
+type UserTokenService interface {
+	// Stores a new user token.
+	CreateUserToken(t *UserToken) error
+
+	// Atomically marks the token having given purpose & hash as used and
+	// returns it. Returns ErrNotFound if token does not exist, is expired
+	// or already used.
+	ConsumeUserToken(purpose string, hash string) (*UserToken, error)
+
+	// Expires all unused tokens of the user, e.g. once the password is set,
+	// so earlier reset & signin links can't be used anymore.
+	ExpireUserTokens(userID int) error
+}